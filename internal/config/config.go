@@ -0,0 +1,56 @@
+// Package config loads claude-manager's optional user configuration from
+// ~/.config/claude-manager/config.json (or the platform equivalent).
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"claude-manager/internal/pricing"
+)
+
+// Config is the user configuration. Every field is optional.
+type Config struct {
+	// Pricing adds or overrides entries in the built-in price table,
+	// keyed by model name prefix.
+	Pricing pricing.Table `json:"pricing"`
+
+	// Roots lists the Claude data directories to load sessions from, each
+	// as "dir" or "name=dir", e.g. "work=~/.claude-work".
+	Roots []string `json:"roots"`
+}
+
+// Dir returns claude-manager's configuration directory.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "claude-manager"), nil
+}
+
+// Load reads the config file. A missing file yields an empty Config.
+func Load() (Config, error) {
+	var cfg Config
+	dir, err := Dir()
+	if err != nil {
+		return cfg, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// PriceTable returns the built-in price table with the user's overrides applied.
+func (c Config) PriceTable() pricing.Table {
+	return pricing.Default().Merge(c.Pricing)
+}
@@ -0,0 +1,304 @@
+// Package export renders a session transcript as Markdown, HTML or JSON.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"claude-manager/internal/sessions"
+)
+
+// Formats lists the supported export formats.
+var Formats = []string{"md", "html", "json"}
+
+// Write renders the session and its transcript in the given format.
+func Write(w io.Writer, s sessions.Session, turns []sessions.Turn, format string) error {
+	switch format {
+	case "md", "markdown":
+		return Markdown(w, s, turns)
+	case "html":
+		return HTML(w, s, turns)
+	case "json":
+		return JSON(w, s, turns)
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+// metadata returns the label/value pairs shown in every export's header.
+func metadata(s sessions.Session) [][2]string {
+	rows := [][2]string{
+		{"Project", s.Project},
+		{"Path", s.ProjectPath},
+		{"Branch", s.GitBranch},
+		{"Started", formatTime(s.Created)},
+		{"Last active", formatTime(s.LastActive)},
+		{"Messages", fmt.Sprintf("%d", s.MessageCount)},
+		{"Session ID", s.ID},
+	}
+	var out [][2]string
+	for _, r := range rows {
+		if r[1] != "" {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// roleName names who wrote a turn; sub-agent turns are marked as such.
+func roleName(t sessions.Turn) string {
+	name := "User"
+	if t.Role == "assistant" {
+		name = "Claude"
+	}
+	if t.Sidechain > 0 {
+		name += fmt.Sprintf(" (sub-agent %d)", t.Sidechain)
+	}
+	return name
+}
+
+// toolSummary is the one-line label of a folded tool, result or thinking block.
+func toolSummary(b sessions.Block) string {
+	switch b.Kind {
+	case sessions.BlockToolUse:
+		return "Tool: " + b.ToolName
+	case sessions.BlockToolResult:
+		if b.IsError {
+			return "Tool error"
+		}
+		return "Tool result"
+	case sessions.BlockThinking:
+		return "Thinking"
+	}
+	return ""
+}
+
+// fence returns a code fence longer than any backtick run inside content.
+func fence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// Markdown writes the transcript as GitHub-flavored Markdown. Tool calls,
+// tool results and thinking are collapsed into <details> elements.
+func Markdown(w io.Writer, s sessions.Session, turns []sessions.Turn) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", s.Summary)
+	for _, r := range metadata(s) {
+		fmt.Fprintf(&b, "- **%s:** %s\n", r[0], r[1])
+	}
+	b.WriteString("\n")
+
+	for _, t := range turns {
+		fmt.Fprintf(&b, "## %s", roleName(t))
+		if ts := formatTime(t.Timestamp); ts != "" {
+			fmt.Fprintf(&b, " · %s", ts)
+		}
+		b.WriteString("\n\n")
+
+		for _, blk := range t.Blocks {
+			if blk.Kind == sessions.BlockText {
+				b.WriteString(blk.Text)
+				b.WriteString("\n\n")
+				continue
+			}
+			body, lang := blk.Text, ""
+			if blk.Kind == sessions.BlockToolUse {
+				body, lang = blk.ToolInput, "json"
+			}
+			f := fence(body)
+			fmt.Fprintf(&b, "<details>\n<summary>%s</summary>\n\n%s%s\n%s\n%s\n\n</details>\n\n",
+				html.EscapeString(toolSummary(blk)), f, lang, body, f)
+		}
+	}
+
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+const htmlStyle = `body{font-family:-apple-system,BlinkMacSystemFont,"Segoe UI",Helvetica,Arial,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;line-height:1.5;color:#1a1a1a}
+h1{font-size:1.5em}
+dl.meta{display:grid;grid-template-columns:max-content auto;gap:.2em 1em;color:#555}
+dl.meta dt{font-weight:600}
+dl.meta dd{margin:0}
+.turn{border-top:1px solid #ddd;padding-top:.5em;margin-top:1em}
+.turn h2{font-size:1em;color:#7D56F4;margin:.5em 0}
+.turn.user h2{color:#43BF6D}
+.turn time{color:#888;font-weight:normal;margin-left:.5em}
+pre{background:#f6f8fa;padding:.75em;overflow-x:auto;border-radius:4px}
+details{margin:.5em 0}
+summary{cursor:pointer;color:#555}
+details.error summary{color:#D7263D}`
+
+// HTML writes the transcript as a standalone HTML page.
+func HTML(w io.Writer, s sessions.Session, turns []sessions.Turn) error {
+	var b strings.Builder
+	title := html.EscapeString(s.Summary)
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n<style>\n%s\n</style>\n</head>\n<body>\n", title, htmlStyle)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<dl class=\"meta\">\n", title)
+	for _, r := range metadata(s) {
+		fmt.Fprintf(&b, "<dt>%s</dt><dd>%s</dd>\n", html.EscapeString(r[0]), html.EscapeString(r[1]))
+	}
+	b.WriteString("</dl>\n")
+
+	for _, t := range turns {
+		fmt.Fprintf(&b, "<section class=\"turn %s\">\n<h2>%s", t.Role, roleName(t))
+		if ts := formatTime(t.Timestamp); ts != "" {
+			fmt.Fprintf(&b, "<time>%s</time>", ts)
+		}
+		b.WriteString("</h2>\n")
+
+		for _, blk := range t.Blocks {
+			if blk.Kind == sessions.BlockText {
+				b.WriteString(htmlText(blk.Text))
+				continue
+			}
+			body := blk.Text
+			if blk.Kind == sessions.BlockToolUse {
+				body = blk.ToolInput
+			}
+			class := ""
+			if blk.IsError {
+				class = " class=\"error\""
+			}
+			fmt.Fprintf(&b, "<details%s>\n<summary>%s</summary>\n<pre><code>%s</code></pre>\n</details>\n",
+				class, html.EscapeString(toolSummary(blk)), html.EscapeString(body))
+		}
+		b.WriteString("</section>\n")
+	}
+
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// htmlText converts message text to HTML: fenced code blocks become <pre>
+// elements and the remaining text becomes paragraphs with line breaks.
+func htmlText(text string) string {
+	var b strings.Builder
+	var para, code []string
+	inCode := false
+
+	flushPara := func() {
+		if len(para) > 0 {
+			escaped := make([]string, len(para))
+			for i, l := range para {
+				escaped[i] = html.EscapeString(l)
+			}
+			fmt.Fprintf(&b, "<p>%s</p>\n", strings.Join(escaped, "<br>\n"))
+			para = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inCode {
+				fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(strings.Join(code, "\n")))
+				code = nil
+			} else {
+				flushPara()
+			}
+			inCode = !inCode
+			continue
+		}
+		switch {
+		case inCode:
+			code = append(code, line)
+		case strings.TrimSpace(line) == "":
+			flushPara()
+		default:
+			para = append(para, line)
+		}
+	}
+	if inCode {
+		fmt.Fprintf(&b, "<pre><code>%s</code></pre>\n", html.EscapeString(strings.Join(code, "\n")))
+	}
+	flushPara()
+	return b.String()
+}
+
+type jsonSession struct {
+	ID           string     `json:"id"`
+	Summary      string     `json:"summary"`
+	Project      string     `json:"project"`
+	ProjectPath  string     `json:"project_path"`
+	GitBranch    string     `json:"branch"`
+	Created      time.Time  `json:"created"`
+	LastActive   time.Time  `json:"last_active"`
+	MessageCount int        `json:"messages"`
+	Turns        []jsonTurn `json:"turns"`
+}
+
+type jsonTurn struct {
+	Role      string      `json:"role"`
+	Timestamp time.Time   `json:"timestamp"`
+	Sidechain int         `json:"sidechain,omitempty"`
+	Blocks    []jsonBlock `json:"blocks"`
+}
+
+type jsonBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ToolName  string          `json:"tool_name,omitempty"`
+	ToolInput json.RawMessage `json:"tool_input,omitempty"`
+	ToolID    string          `json:"tool_id,omitempty"`
+	IsError   bool            `json:"is_error,omitempty"`
+}
+
+// JSON writes the session metadata and structured transcript as JSON.
+func JSON(w io.Writer, s sessions.Session, turns []sessions.Turn) error {
+	out := jsonSession{
+		ID:           s.ID,
+		Summary:      s.Summary,
+		Project:      s.Project,
+		ProjectPath:  s.ProjectPath,
+		GitBranch:    s.GitBranch,
+		Created:      s.Created,
+		LastActive:   s.LastActive,
+		MessageCount: s.MessageCount,
+		Turns:        []jsonTurn{},
+	}
+	for _, t := range turns {
+		jt := jsonTurn{Role: t.Role, Timestamp: t.Timestamp, Sidechain: t.Sidechain}
+		for _, blk := range t.Blocks {
+			jb := jsonBlock{
+				Type:     string(blk.Kind),
+				Text:     blk.Text,
+				ToolName: blk.ToolName,
+				ToolID:   blk.ToolID,
+				IsError:  blk.IsError,
+			}
+			if blk.ToolInput != "" && json.Valid([]byte(blk.ToolInput)) {
+				jb.ToolInput = json.RawMessage(blk.ToolInput)
+			}
+			jt.Blocks = append(jt.Blocks, jb)
+		}
+		out.Turns = append(out.Turns, jt)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
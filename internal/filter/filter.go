@@ -0,0 +1,137 @@
+// Package filter selects and orders sessions. It is shared by the TUI search
+// box and the `list` command so both behave the same.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"claude-manager/internal/sessions"
+)
+
+// Options restricts which sessions are kept. Zero values match everything.
+type Options struct {
+	Project     string    // substring of the project name
+	Branch      string    // substring of the git branch
+	PathPrefix  string    // prefix of the project path
+	Since       time.Time // last active at or after
+	Until       time.Time // last active before
+	MinMessages int
+	Grep        string    // words or phrase in any message, looked up in Index
+	Tag         string    // exact tag, with or without a leading '#'
+	Index       TextIndex // full-text index; without one, Grep matches nothing
+}
+
+// Apply returns the sessions matching every option, preserving order.
+func Apply(all []sessions.Session, o Options) []sessions.Session {
+	if o.Project != "" {
+		all = ByProject(all, o.Project)
+	}
+	if o.Tag != "" {
+		all = ByTag(all, o.Tag)
+	}
+	branch := strings.ToLower(o.Branch)
+	var grep map[string]bool
+	if o.Grep != "" && o.Index != nil {
+		grep, _ = o.Index.Files(o.Grep)
+	}
+	var result []sessions.Session
+	for _, s := range all {
+		if branch != "" && !strings.Contains(strings.ToLower(s.GitBranch), branch) {
+			continue
+		}
+		if o.PathPrefix != "" && !strings.HasPrefix(s.ProjectPath, o.PathPrefix) {
+			continue
+		}
+		if !o.Since.IsZero() && s.LastActive.Before(o.Since) {
+			continue
+		}
+		if !o.Until.IsZero() && !s.LastActive.Before(o.Until) {
+			continue
+		}
+		if s.MessageCount < o.MinMessages {
+			continue
+		}
+		if o.Grep != "" && !grep[s.FilePath] {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// ByProject returns sessions whose project name contains the given substring.
+func ByProject(all []sessions.Session, project string) []sessions.Session {
+	p := strings.ToLower(project)
+	var result []sessions.Session
+	for _, s := range all {
+		if strings.Contains(strings.ToLower(s.Project), p) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// ByTag returns sessions carrying the given tag. Tags are lowercase, so the
+// match ignores case; a leading '#' is ignored.
+func ByTag(all []sessions.Session, tag string) []sessions.Session {
+	tag = strings.ToLower(strings.TrimPrefix(tag, "#"))
+	var result []sessions.Session
+	for _, s := range all {
+		for _, t := range s.Tags {
+			if t == tag {
+				result = append(result, s)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// SortKey selects the order of a session list.
+type SortKey string
+
+const (
+	SortLastActive SortKey = "last-active" // most recently active first
+	SortCreated    SortKey = "created"     // most recently started first
+	SortMessages   SortKey = "messages"    // most messages first
+	SortProject    SortKey = "project"     // by project name, then most recently active
+)
+
+// ParseSortKey validates a --sort value.
+func ParseSortKey(s string) (SortKey, error) {
+	switch k := SortKey(s); k {
+	case SortLastActive, SortCreated, SortMessages, SortProject:
+		return k, nil
+	}
+	return "", fmt.Errorf("unknown sort %q (want last-active, created, messages or project)", s)
+}
+
+// Sort orders sessions in place by key, pinned sessions first. Ties keep
+// their existing order.
+func Sort(ss []sessions.Session, key SortKey) {
+	var less func(a, b sessions.Session) bool
+	switch key {
+	case SortCreated:
+		less = func(a, b sessions.Session) bool { return a.Created.After(b.Created) }
+	case SortMessages:
+		less = func(a, b sessions.Session) bool { return a.MessageCount > b.MessageCount }
+	case SortProject:
+		less = func(a, b sessions.Session) bool {
+			if a.Project != b.Project {
+				return strings.ToLower(a.Project) < strings.ToLower(b.Project)
+			}
+			return a.LastActive.After(b.LastActive)
+		}
+	default:
+		less = func(a, b sessions.Session) bool { return a.LastActive.After(b.LastActive) }
+	}
+	sort.SliceStable(ss, func(i, j int) bool {
+		if ss[i].Pinned != ss[j].Pinned {
+			return ss[i].Pinned
+		}
+		return less(ss[i], ss[j])
+	})
+}
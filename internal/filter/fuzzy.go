@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Scoring weights, loosely after fzf: every matched character scores, more so
+// at the start of a word or right after the previous match, and gaps between
+// matched characters cost a little.
+const (
+	scoreMatch        = 16
+	bonusBoundary     = 8
+	bonusCamel        = 7
+	bonusConsecutive  = 4
+	bonusFirstChar    = 2 // multiplier for the boundary bonus of the first character
+	penaltyGapStart   = 3
+	penaltyGapExtends = 1
+)
+
+// FuzzyMatch reports whether the characters of pattern appear in order in
+// text, ignoring case. It returns a score (higher is better) and the rune
+// positions in text that matched. Of all the places the pattern could match,
+// it scores the shortest one ending at the earliest possible position.
+func FuzzyMatch(pattern, text string) (score int, positions []int, ok bool) {
+	pat := []rune(strings.ToLower(pattern))
+	if len(pat) == 0 {
+		return 0, nil, true
+	}
+	orig := []rune(text)
+	runes := []rune(strings.ToLower(text))
+	if len(runes) != len(orig) {
+		// Lowercasing changed the rune count; fall back to the original.
+		runes = orig
+	}
+
+	// Forward pass: find where the earliest match ends.
+	pi, end := 0, -1
+	for i, r := range runes {
+		if r == pat[pi] {
+			pi++
+			if pi == len(pat) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// Backward pass: walk back from the end to find the tightest start.
+	pi = len(pat) - 1
+	start := end
+	for i := end; i >= 0; i-- {
+		if runes[i] == pat[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	// Score the window greedily from the start.
+	positions = make([]int, 0, len(pat))
+	pi = 0
+	inGap, prevMatched := false, false
+	for i := start; i <= end && pi < len(pat); i++ {
+		if runes[i] != pat[pi] {
+			if inGap {
+				score -= penaltyGapExtends
+			} else {
+				score -= penaltyGapStart
+			}
+			inGap, prevMatched = true, false
+			continue
+		}
+		s := scoreMatch
+		b := boundaryBonus(orig, i)
+		if pi == 0 {
+			b *= bonusFirstChar
+		}
+		s += b
+		if prevMatched {
+			s += bonusConsecutive
+		}
+		score += s
+		positions = append(positions, i)
+		pi++
+		inGap, prevMatched = false, true
+	}
+	return score, positions, true
+}
+
+// boundaryBonus rewards matches at the start of a word or a camelCase hump.
+func boundaryBonus(text []rune, i int) int {
+	if i == 0 {
+		return bonusBoundary
+	}
+	prev, cur := text[i-1], text[i]
+	switch {
+	case !unicode.IsLetter(prev) && !unicode.IsDigit(prev):
+		return bonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return bonusCamel
+	}
+	return 0
+}
+
+// substringPositions returns the rune positions of the first case-insensitive
+// occurrence of sub in text, or nil if there is none.
+func substringPositions(sub, text string) []int {
+	if sub == "" {
+		return nil
+	}
+	lower := []rune(strings.ToLower(text))
+	needle := []rune(strings.ToLower(sub))
+	if len(lower) != len([]rune(text)) {
+		return nil
+	}
+	for i := 0; i+len(needle) <= len(lower); i++ {
+		if string(lower[i:i+len(needle)]) == string(needle) {
+			positions := make([]int, len(needle))
+			for j := range needle {
+				positions[j] = i + j
+			}
+			return positions
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		pattern, text string
+		ok            bool
+		positions     []int
+	}{
+		{"", "anything", true, nil},
+		{"abc", "abc", true, []int{0, 1, 2}},
+		{"ABC", "xabcx", true, []int{1, 2, 3}},
+		{"flb", "fix login bug", true, []int{0, 4, 10}},
+		{"bug", "fix login bug", true, []int{10, 11, 12}},
+		{"gb", "GitBranch", true, []int{0, 3}},
+		// The tightest window ending earliest wins over the first 'a'.
+		{"ab", "a xx ab", true, []int{5, 6}},
+		{"né", "Résumé né", true, []int{7, 8}},
+		{"cba", "abc", false, nil},
+		{"abcd", "abc", false, nil},
+		{"x", "", false, nil},
+	}
+	for _, tt := range tests {
+		_, positions, ok := FuzzyMatch(tt.pattern, tt.text)
+		if ok != tt.ok || !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("FuzzyMatch(%q, %q) = %v, %v; want %v, %v",
+				tt.pattern, tt.text, positions, ok, tt.positions, tt.ok)
+		}
+	}
+}
+
+func TestFuzzyMatchScores(t *testing.T) {
+	// Each pair is (better, worse) text for the same pattern.
+	tests := []struct {
+		pattern, better, worse string
+	}{
+		{"login", "login", "lxoxgxixn"},                 // consecutive beats scattered
+		{"bug", "fix bug", "debugger"},                  // word start beats mid-word
+		{"fb", "fooBar", "foobar"},                      // camelCase hump
+		{"ab", "a b", "a xxxxx b"},                      // shorter gap
+		{"mode", "dark_mode", "darkmode"},               // '_' is a word boundary
+		{"lb", "login bug", "login and the other bug"},  // gaps cost
+		{"api", "api server", "a python interpreter"},   // consecutive again
+		{"test", "run tests", "the easy script tester"}, // fewer gaps
+	}
+	for _, tt := range tests {
+		b, _, okB := FuzzyMatch(tt.pattern, tt.better)
+		w, _, okW := FuzzyMatch(tt.pattern, tt.worse)
+		if !okB || !okW {
+			t.Errorf("%q: expected both %q and %q to match", tt.pattern, tt.better, tt.worse)
+			continue
+		}
+		if b <= w {
+			t.Errorf("%q: score %d for %q, want more than %d for %q", tt.pattern, b, tt.better, w, tt.worse)
+		}
+	}
+}
+
+func TestSubstringPositions(t *testing.T) {
+	tests := []struct {
+		sub, text string
+		want      []int
+	}{
+		{"bug", "Fix login BUG", []int{10, 11, 12}},
+		{"é", "résumé", []int{1}},
+		{"none", "fix login", nil},
+		{"", "fix", nil},
+	}
+	for _, tt := range tests {
+		if got := substringPositions(tt.sub, tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("substringPositions(%q, %q) = %v, want %v", tt.sub, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,544 @@
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"claude-manager/internal/sessions"
+)
+
+// Query is a parsed search query, as typed in the TUI search box or passed
+// to `list --query`. Its grammar:
+//
+//	login bug            both words, anywhere in summary, project, branch or note
+//	"login bug"          the exact phrase
+//	-draft               negation; also -branch:main, -(a OR b)
+//	a OR b, a AND b      AND is implied between terms and binds tighter than OR
+//	(a OR b) c           grouping
+//	@api, #wip           project name, exact tag
+//	project: branch: path: model: note: root: tag: id: text:
+//	                     qualifiers; id: is an ID prefix, text: searches
+//	                     message content, the rest are substrings
+//	since:2w before:2025-01-01 (until: is an alias)
+//	                     last active at or after / before
+//	msgs:>20             message count; >, >=, <, <=, = or a plain number
+//
+// Values may be quoted: branch:"fix login". Matching ignores case.
+//
+// Unless Mode.Exact is set, bare words match fuzzily against the summary,
+// project and branch, and Filter ranks results by how well they match.
+// Quoted phrases always match exactly.
+//
+// Message content is looked up in Mode.Index: every message, assistant
+// replies and tool output included, with words matching as prefixes. text:
+// values always search it, and in full-text mode bare words and phrases do
+// too. Without an index, nothing matches message content.
+type Query struct {
+	match   matcher  // nil matches everything
+	terms   []string // words and phrases that must match, for ranking and highlighting
+	exact   []bool   // whether each of terms was quoted
+	texts   []string // every bare word and phrase, negated ones included
+	lookups []string // text: values
+}
+
+// Mode selects how a query's words match.
+type Mode struct {
+	FullText bool      // words may also match message content
+	Exact    bool      // substring instead of fuzzy matching
+	Index    TextIndex // full-text index of all messages; optional
+
+	hits map[string]map[string]bool // word or phrase -> matching session files
+}
+
+// TextIndex looks up the session files with a message containing text. ok is
+// false when text cannot be looked up, e.g. because it is too short.
+type TextIndex interface {
+	Files(text string) (files map[string]bool, ok bool)
+}
+
+// matcher reports whether a session matches.
+type matcher func(s *sessions.Session, mode Mode) bool
+
+// ParseQuery parses a query string. An empty query matches every session.
+func ParseQuery(input string) (Query, error) {
+	p := &queryParser{tokens: lexQuery(input), now: time.Now()}
+	if len(p.tokens) == 0 {
+		return Query{}, nil
+	}
+	m, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if p.pos < len(p.tokens) {
+		return Query{}, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return Query{match: m, terms: p.terms, exact: p.exact, texts: p.texts, lookups: p.lookups}, nil
+}
+
+// SearchesText reports whether the query has text: qualifiers, which need
+// Mode.Index.
+func (q Query) SearchesText() bool {
+	return len(q.lookups) > 0
+}
+
+// Match reports whether a session satisfies the query.
+func (q Query) Match(s sessions.Session, mode Mode) bool {
+	return q.match == nil || q.match(&s, mode)
+}
+
+// Terms returns the bare words and phrases a matching session must contain,
+// leaving out negated ones and qualifiers.
+func (q Query) Terms() []string {
+	return q.terms
+}
+
+// Filter returns the sessions matching the query. In fuzzy mode they are
+// ranked by score, best first; ties, and every result in exact mode, keep
+// their order from all.
+func (q Query) Filter(all []sessions.Session, mode Mode) []sessions.Session {
+	if q.match == nil {
+		return all
+	}
+	if mode.Index != nil {
+		mode.hits = make(map[string]map[string]bool)
+		lookup := q.lookups
+		if mode.FullText {
+			lookup = append(lookup[:len(lookup):len(lookup)], q.texts...)
+		}
+		for _, text := range lookup {
+			if _, done := mode.hits[text]; done {
+				continue
+			}
+			if files, ok := mode.Index.Files(text); ok {
+				mode.hits[text] = files
+			}
+		}
+	}
+	var result []sessions.Session
+	var scores []int
+	for i := range all {
+		if q.match(&all[i], mode) {
+			result = append(result, all[i])
+			if !mode.Exact {
+				scores = append(scores, q.score(&all[i]))
+			}
+		}
+	}
+	if !mode.Exact && len(q.terms) > 0 {
+		sort.Stable(byScore{result, scores})
+	}
+	return result
+}
+
+type byScore struct {
+	ss     []sessions.Session
+	scores []int
+}
+
+func (b byScore) Len() int           { return len(b.ss) }
+func (b byScore) Less(i, j int) bool { return b.scores[i] > b.scores[j] }
+func (b byScore) Swap(i, j int) {
+	b.ss[i], b.ss[j] = b.ss[j], b.ss[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+}
+
+// score sums each fuzzy word's best score across the summary, project and
+// branch. Words that only matched elsewhere add nothing.
+func (q Query) score(s *sessions.Session) int {
+	total := 0
+	for i, term := range q.terms {
+		if q.exact[i] {
+			continue
+		}
+		best := 0
+		for _, field := range fuzzyFields(s) {
+			if sc, _, ok := FuzzyMatch(term, field); ok && sc > best {
+				best = sc
+			}
+		}
+		total += best
+	}
+	return total
+}
+
+// Highlight returns the rune positions in text matched by the query's words,
+// in ascending order, for highlighting a displayed field.
+func (q Query) Highlight(text string, mode Mode) []int {
+	seen := make(map[int]bool)
+	var out []int
+	for i, term := range q.terms {
+		var positions []int
+		if mode.Exact || q.exact[i] {
+			positions = substringPositions(term, text)
+		} else {
+			_, positions, _ = FuzzyMatch(term, text)
+		}
+		for _, p := range positions {
+			if !seen[p] {
+				seen[p] = true
+				out = append(out, p)
+			}
+		}
+	}
+	sort.Ints(out)
+	return out
+}
+
+// token is one lexical element of a query. bare is the part of text before
+// its first double quote: only that part can carry a '-', '@', '#' or
+// qualifier prefix, and a quoted "OR" is just a word.
+type token struct {
+	text   string
+	bare   string
+	quoted bool
+	paren  bool // text is "(" or ")"
+}
+
+// lexQuery splits a query into words, quoted phrases and parentheses. Quotes
+// may start mid-word, as in branch:"fix login"; an unterminated quote runs to
+// the end of the input.
+func lexQuery(input string) []token {
+	var tokens []token
+	var cur strings.Builder
+	bareLen := -1 // length of the text before the first quote, once seen
+	inQuote, started := false, false
+	flush := func() {
+		if started {
+			t := token{text: cur.String(), bare: cur.String()}
+			if bareLen >= 0 {
+				t.bare, t.quoted = t.text[:bareLen], true
+			}
+			tokens = append(tokens, t)
+		}
+		cur.Reset()
+		bareLen, started = -1, false
+	}
+	for _, r := range input {
+		switch {
+		case r == '"':
+			if bareLen < 0 {
+				bareLen = cur.Len()
+			}
+			inQuote = !inQuote
+			started = true
+		case inQuote:
+			cur.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\n':
+			flush()
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, token{text: string(r), bare: string(r), paren: true})
+		default:
+			cur.WriteRune(r)
+			started = true
+		}
+	}
+	flush()
+	return tokens
+}
+
+type queryParser struct {
+	tokens  []token
+	pos     int
+	now     time.Time
+	negated int // depth of negations around the term being parsed
+	terms   []string
+	exact   []bool
+	texts   []string
+	lookups []string
+}
+
+func (p *queryParser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func isKeyword(t token, kw string) bool {
+	return !t.quoted && !t.paren && t.text == kw
+}
+
+func isParen(t token, paren string) bool {
+	return t.paren && t.text == paren
+}
+
+func (p *queryParser) parseOr() (matcher, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	alts := []matcher{first}
+	for {
+		t, ok := p.peek()
+		if !ok || !isKeyword(t, "OR") {
+			break
+		}
+		p.pos++
+		m, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		alts = append(alts, m)
+	}
+	if len(alts) == 1 {
+		return first, nil
+	}
+	return func(s *sessions.Session, mode Mode) bool {
+		for _, m := range alts {
+			if m(s, mode) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+func (p *queryParser) parseAnd() (matcher, error) {
+	var all []matcher
+	for {
+		t, ok := p.peek()
+		if !ok || isKeyword(t, "OR") || isParen(t, ")") {
+			break
+		}
+		if isKeyword(t, "AND") {
+			p.pos++
+			continue
+		}
+		m, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, m)
+	}
+	switch len(all) {
+	case 0:
+		if t, ok := p.peek(); ok {
+			return nil, fmt.Errorf("expected a search term before %q", t.text)
+		}
+		return nil, fmt.Errorf("expected a search term at the end")
+	case 1:
+		return all[0], nil
+	}
+	return func(s *sessions.Session, mode Mode) bool {
+		for _, m := range all {
+			if !m(s, mode) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func (p *queryParser) parseUnary() (matcher, error) {
+	t := p.tokens[p.pos]
+	p.pos++
+
+	if t.paren {
+		if t.text == ")" {
+			return nil, fmt.Errorf("unexpected \")\"")
+		}
+		m, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || !isParen(next, ")") {
+			return nil, fmt.Errorf("missing \")\"")
+		}
+		p.pos++
+		return m, nil
+	}
+
+	if strings.HasPrefix(t.bare, "-") {
+		var inner matcher
+		var err error
+		p.negated++
+		defer func() { p.negated-- }()
+		if t.text == "-" && !t.quoted {
+			// A lone "-" negates the group that follows: -(a OR b).
+			if _, ok := p.peek(); !ok {
+				return nil, fmt.Errorf("nothing to negate after \"-\"")
+			}
+			inner, err = p.parseUnary()
+		} else {
+			inner, err = p.term(token{text: t.text[1:], bare: t.bare[1:], quoted: t.quoted})
+		}
+		if err != nil {
+			return nil, err
+		}
+		return func(s *sessions.Session, mode Mode) bool { return !inner(s, mode) }, nil
+	}
+
+	return p.term(t)
+}
+
+// term builds the matcher for a single word, phrase or qualifier.
+func (p *queryParser) term(t token) (matcher, error) {
+	switch {
+	case strings.HasPrefix(t.bare, "@"):
+		return p.qualifier("project", t.text[1:])
+	case strings.HasPrefix(t.bare, "#"):
+		return p.qualifier("tag", t.text[1:])
+	}
+	if field, _, ok := strings.Cut(t.bare, ":"); ok {
+		if _, known := qualifiers[strings.ToLower(field)]; known {
+			return p.qualifier(strings.ToLower(field), t.text[len(field)+1:])
+		}
+	}
+	if p.negated == 0 {
+		p.terms = append(p.terms, t.text)
+		p.exact = append(p.exact, t.quoted)
+	}
+	p.texts = append(p.texts, t.text)
+	return textMatcher(t.text, t.quoted), nil
+}
+
+// qualifiers maps each field: name to a constructor for its matcher.
+var qualifiers = map[string]func(p *queryParser, value string) (matcher, error){
+	"project": func(_ *queryParser, v string) (matcher, error) {
+		return fieldMatcher(v, func(s *sessions.Session) []string { return []string{s.Project} }), nil
+	},
+	"branch": func(_ *queryParser, v string) (matcher, error) {
+		return fieldMatcher(v, func(s *sessions.Session) []string { return []string{s.GitBranch} }), nil
+	},
+	"path": func(_ *queryParser, v string) (matcher, error) {
+		return fieldMatcher(v, func(s *sessions.Session) []string { return []string{s.ProjectPath} }), nil
+	},
+	"note": func(_ *queryParser, v string) (matcher, error) {
+		return fieldMatcher(v, func(s *sessions.Session) []string { return []string{s.Note} }), nil
+	},
+	"root": func(_ *queryParser, v string) (matcher, error) {
+		return fieldMatcher(v, func(s *sessions.Session) []string { return []string{s.Root} }), nil
+	},
+	"text": func(p *queryParser, v string) (matcher, error) {
+		p.lookups = append(p.lookups, v)
+		return func(s *sessions.Session, mode Mode) bool {
+			return mode.hits[v][s.FilePath]
+		}, nil
+	},
+	"model": func(_ *queryParser, v string) (matcher, error) {
+		return fieldMatcher(v, func(s *sessions.Session) []string {
+			models := make([]string, 0, len(s.Usage))
+			for m := range s.Usage {
+				models = append(models, m)
+			}
+			return models
+		}), nil
+	},
+	"tag": func(_ *queryParser, v string) (matcher, error) {
+		tag := strings.ToLower(strings.TrimPrefix(v, "#"))
+		return func(s *sessions.Session, _ Mode) bool {
+			for _, t := range s.Tags {
+				if t == tag {
+					return true
+				}
+			}
+			return false
+		}, nil
+	},
+	"id": func(_ *queryParser, v string) (matcher, error) {
+		v = strings.ToLower(v)
+		return func(s *sessions.Session, _ Mode) bool { return strings.HasPrefix(s.ID, v) }, nil
+	},
+	"since": func(p *queryParser, v string) (matcher, error) {
+		t, err := sessions.ParseTime(v, p.now)
+		if err != nil {
+			return nil, fmt.Errorf("since: %w", err)
+		}
+		return func(s *sessions.Session, _ Mode) bool { return !s.LastActive.Before(t) }, nil
+	},
+	"before": parseBefore,
+	"until":  parseBefore,
+	"msgs": func(_ *queryParser, v string) (matcher, error) {
+		return parseCount(v)
+	},
+}
+
+func parseBefore(p *queryParser, v string) (matcher, error) {
+	t, err := sessions.ParseTime(v, p.now)
+	if err != nil {
+		return nil, fmt.Errorf("before: %w", err)
+	}
+	return func(s *sessions.Session, _ Mode) bool { return s.LastActive.Before(t) }, nil
+}
+
+// qualifier builds the matcher for field:value. An empty value, as while
+// the query is still being typed, matches everything.
+func (p *queryParser) qualifier(field, value string) (matcher, error) {
+	if value == "" {
+		return func(*sessions.Session, Mode) bool { return true }, nil
+	}
+	return qualifiers[field](p, value)
+}
+
+// parseCount parses a msgs: comparison such as ">20", "<=5" or "10".
+func parseCount(v string) (matcher, error) {
+	op := strings.TrimRight(v, "0123456789")
+	n, err := strconv.Atoi(v[len(op):])
+	if err != nil {
+		return nil, fmt.Errorf("msgs: invalid count %q (use e.g. >20, <=5, 10)", v)
+	}
+	var cmp func(c int) bool
+	switch op {
+	case ">":
+		cmp = func(c int) bool { return c > n }
+	case ">=":
+		cmp = func(c int) bool { return c >= n }
+	case "<":
+		cmp = func(c int) bool { return c < n }
+	case "<=":
+		cmp = func(c int) bool { return c <= n }
+	case "", "=":
+		cmp = func(c int) bool { return c == n }
+	default:
+		return nil, fmt.Errorf("msgs: invalid comparison %q (use >, >=, <, <= or =)", op)
+	}
+	return func(s *sessions.Session, _ Mode) bool { return cmp(s.MessageCount) }, nil
+}
+
+// fieldMatcher matches sessions where any of the values contains sub.
+func fieldMatcher(sub string, values func(s *sessions.Session) []string) matcher {
+	sub = strings.ToLower(sub)
+	return func(s *sessions.Session, _ Mode) bool {
+		for _, v := range values(s) {
+			if strings.Contains(strings.ToLower(v), sub) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// fuzzyFields returns the fields bare words are fuzzy-matched against: the
+// summary (and the original summary behind a custom title), project and branch.
+func fuzzyFields(s *sessions.Session) []string {
+	return []string{s.Summary, s.OriginalSummary, s.Project, s.GitBranch}
+}
+
+// textMatcher matches a word or phrase against the fuzzy fields, the note
+// and, in full-text mode, message content. Only unquoted words in fuzzy mode
+// match the fuzzy fields fuzzily; the note is a substring match.
+func textMatcher(text string, quoted bool) matcher {
+	q := strings.ToLower(text)
+	return func(s *sessions.Session, mode Mode) bool {
+		for _, v := range fuzzyFields(s) {
+			if mode.Exact || quoted {
+				if strings.Contains(strings.ToLower(v), q) {
+					return true
+				}
+			} else if _, _, ok := FuzzyMatch(text, v); ok {
+				return true
+			}
+		}
+		if strings.Contains(strings.ToLower(s.Note), q) {
+			return true
+		}
+		if !mode.FullText {
+			return false
+		}
+		return mode.hits[text][s.FilePath]
+	}
+}
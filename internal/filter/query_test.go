@@ -0,0 +1,180 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"claude-manager/internal/sessions"
+)
+
+// testIndex is a TextIndex over a fixed set of lookups.
+type testIndex map[string]map[string]bool
+
+func (ix testIndex) Files(text string) (map[string]bool, bool) {
+	files, ok := ix[text]
+	return files, ok
+}
+
+func testSessions() []sessions.Session {
+	now := time.Now()
+	return []sessions.Session{
+		{
+			ID: "aaaa1111", Project: "api", GitBranch: "main", ProjectPath: "/src/api",
+			Summary: "Fix login bug", MessageCount: 30, LastActive: now.Add(-time.Hour),
+			FilePath: "/p/a.jsonl", Tags: []string{"wip"}, Note: "waiting on review",
+			Usage: map[string]sessions.TokenUsage{"claude-sonnet-4": {}},
+		},
+		{
+			ID: "bbbb2222", Project: "web", GitBranch: "fix-login", ProjectPath: "/src/web",
+			Summary: "Add dark mode", MessageCount: 5, LastActive: now.Add(-48 * time.Hour),
+			FilePath: "/p/b.jsonl", Root: "work",
+			Usage: map[string]sessions.TokenUsage{"claude-opus-4": {}},
+		},
+		{
+			ID: "cccc3333", Project: "api", GitBranch: "draft", ProjectPath: "/src/api",
+			Summary: "Draft release notes", MessageCount: 12, LastActive: now.Add(-30 * 24 * time.Hour),
+			FilePath: "/p/c.jsonl",
+		},
+	}
+}
+
+func ids(ss []sessions.Session) []string {
+	out := []string{}
+	for _, s := range ss {
+		out = append(out, s.ID[:4])
+	}
+	return out
+}
+
+func TestParseQueryMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		mode  Mode
+		want  []string
+	}{
+		{"", Mode{}, []string{"aaaa", "bbbb", "cccc"}},
+		{"login", Mode{Exact: true}, []string{"aaaa", "bbbb"}},
+		{"fix bug", Mode{Exact: true}, []string{"aaaa"}},
+		{`"login bug"`, Mode{}, []string{"aaaa"}},
+		{`"bug login"`, Mode{}, []string{}},
+		{"LOGIN", Mode{Exact: true}, []string{"aaaa", "bbbb"}},
+		{"review", Mode{Exact: true}, []string{"aaaa"}},
+
+		// Qualifiers.
+		{"project:api", Mode{}, []string{"aaaa", "cccc"}},
+		{"@web", Mode{}, []string{"bbbb"}},
+		{"branch:fix", Mode{}, []string{"bbbb"}},
+		{`branch:"fix-login"`, Mode{}, []string{"bbbb"}},
+		{"path:/src/web", Mode{}, []string{"bbbb"}},
+		{"model:opus", Mode{}, []string{"bbbb"}},
+		{"note:review", Mode{}, []string{"aaaa"}},
+		{"root:work", Mode{}, []string{"bbbb"}},
+		{"#wip", Mode{}, []string{"aaaa"}},
+		{"tag:#wip", Mode{}, []string{"aaaa"}},
+		{"id:CCCC", Mode{}, []string{"cccc"}},
+		{"since:1d", Mode{}, []string{"aaaa"}},
+		{"before:1w", Mode{}, []string{"cccc"}},
+		{"until:1w", Mode{}, []string{"cccc"}},
+		{"msgs:>12", Mode{}, []string{"aaaa"}},
+		{"msgs:>=12", Mode{}, []string{"aaaa", "cccc"}},
+		{"msgs:<12", Mode{}, []string{"bbbb"}},
+		{"msgs:<=12", Mode{}, []string{"bbbb", "cccc"}},
+		{"msgs:5", Mode{}, []string{"bbbb"}},
+		{"msgs:=5", Mode{}, []string{"bbbb"}},
+		{"Project:API", Mode{}, []string{"aaaa", "cccc"}},
+		{"branch:", Mode{}, []string{"aaaa", "bbbb", "cccc"}},
+		{"unknown:x", Mode{Exact: true}, []string{}},
+
+		// Negation.
+		{"-draft", Mode{Exact: true}, []string{"aaaa", "bbbb"}},
+		{"-branch:main", Mode{}, []string{"bbbb", "cccc"}},
+		{"-@api", Mode{}, []string{"bbbb"}},
+		{`-"login bug"`, Mode{}, []string{"bbbb", "cccc"}},
+		{"- (@web OR #wip)", Mode{}, []string{"cccc"}},
+		{"-(@web OR #wip)", Mode{}, []string{"cccc"}},
+
+		// AND binds tighter than OR.
+		{"@web OR @api msgs:>20", Mode{}, []string{"aaaa", "bbbb"}},
+		{"@web OR @api AND msgs:>20", Mode{}, []string{"aaaa", "bbbb"}},
+		{"(@web OR @api) msgs:>20", Mode{}, []string{"aaaa"}},
+		{"@api AND draft", Mode{Exact: true}, []string{"cccc"}},
+		{"(@web OR draft) (#wip OR msgs:<10)", Mode{Exact: true}, []string{"bbbb"}},
+		{`"OR"`, Mode{Exact: true}, []string{}},
+
+		// Message content only matches through the index.
+		{"text:deploy", Mode{}, []string{}},
+		{"text:deploy", Mode{Index: testIndex{"deploy": {"/p/c.jsonl": true}}}, []string{"cccc"}},
+		{"deploy", Mode{Exact: true, Index: testIndex{"deploy": {"/p/c.jsonl": true}}}, []string{}},
+		{"deploy", Mode{Exact: true, FullText: true, Index: testIndex{"deploy": {"/p/c.jsonl": true}}}, []string{"cccc"}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if got := ids(q.Filter(testSessions(), tt.mode)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q matched %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []string{
+		"(login",
+		"login)",
+		")",
+		"()",
+		"OR login",
+		"login OR",
+		"-",
+		"msgs:many",
+		"msgs:!5",
+		"since:someday",
+		"before:soon",
+	}
+	for _, input := range tests {
+		if _, err := ParseQuery(input); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"fix login", []string{"fix", "login"}},
+		{`"login bug" -draft @api msgs:>2`, []string{"login bug"}},
+		{"-(a OR b) c", []string{"c"}},
+		{"text:deploy", nil},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		if got := q.Terms(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryRanksFuzzyMatches(t *testing.T) {
+	ss := []sessions.Session{
+		{ID: "aaaa", Summary: "random"},
+		{ID: "bbbb", Summary: "dark mode"},
+	}
+	q, err := ParseQuery("dm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ids(q.Filter(ss, Mode{})), []string{"bbbb", "aaaa"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("fuzzy order %v, want %v", got, want)
+	}
+	if got, want := ids(q.Filter(ss, Mode{Exact: true})), []string{}; !reflect.DeepEqual(got, want) {
+		t.Errorf("exact matched %v, want %v", got, want)
+	}
+}
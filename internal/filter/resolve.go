@@ -0,0 +1,103 @@
+package filter
+
+import (
+	"sort"
+	"strings"
+
+	"claude-manager/internal/sessions"
+)
+
+// Resolve finds the sessions referred to by ref, which may be:
+//
+//   - "last": the most recently active session
+//   - "last@<project>": the most recently active session in a project
+//   - a full session ID, or a unique prefix of one
+//   - text contained in the session summary or its original summary, or
+//     failing that, words matching them fuzzily, best match first
+//
+// Matches keep the order of ss. More than one result means the reference is
+// ambiguous; none means nothing matched.
+func Resolve(ss []sessions.Session, ref string) []sessions.Session {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || len(ss) == 0 {
+		return nil
+	}
+
+	if ref == "last" {
+		return mostRecent(ss)
+	}
+	if project, ok := strings.CutPrefix(ref, "last@"); ok {
+		return mostRecent(ByProject(ss, project))
+	}
+
+	var prefixed []sessions.Session
+	for _, s := range ss {
+		if s.ID == ref {
+			return []sessions.Session{s}
+		}
+		if strings.HasPrefix(s.ID, ref) {
+			prefixed = append(prefixed, s)
+		}
+	}
+	if len(prefixed) > 0 {
+		return prefixed
+	}
+
+	q := strings.ToLower(ref)
+	var matched []sessions.Session
+	for _, s := range ss {
+		if strings.Contains(strings.ToLower(s.Summary), q) ||
+			strings.Contains(strings.ToLower(s.OriginalSummary), q) {
+			matched = append(matched, s)
+		}
+	}
+	if len(matched) > 0 {
+		return matched
+	}
+
+	// Nothing contains the text; match its words fuzzily instead, best
+	// match first.
+	var scores []int
+	for _, s := range ss {
+		if score, ok := summaryScore(s, strings.Fields(ref)); ok {
+			matched = append(matched, s)
+			scores = append(scores, score)
+		}
+	}
+	sort.Stable(byScore{matched, scores})
+	return matched
+}
+
+// summaryScore sums each word's best fuzzy score against the summary and
+// original summary, and reports whether every word matched one of them.
+func summaryScore(s sessions.Session, words []string) (int, bool) {
+	total := 0
+	for _, w := range words {
+		best, found := 0, false
+		for _, v := range []string{s.Summary, s.OriginalSummary} {
+			if score, _, ok := FuzzyMatch(w, v); ok && (!found || score > best) {
+				best, found = score, true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+		total += best
+	}
+	return total, true
+}
+
+// mostRecent returns the most recently active of ss, which may be sorted with
+// pinned sessions first, or nil if ss is empty.
+func mostRecent(ss []sessions.Session) []sessions.Session {
+	if len(ss) == 0 {
+		return nil
+	}
+	latest := 0
+	for i, s := range ss {
+		if s.LastActive.After(ss[latest].LastActive) {
+			latest = i
+		}
+	}
+	return ss[latest : latest+1]
+}
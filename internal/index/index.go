@@ -0,0 +1,558 @@
+// Package index maintains a persistent inverted index over the content of
+// every session message — user prompts, assistant replies, thinking, tool
+// calls and tool output — for fast full-text search.
+//
+// Each indexed message is a line of a session file, identified by the file
+// and the line's byte offset, so a hit can be read back from disk. Files are
+// indexed incrementally: only bytes appended since the last sync are read.
+// The index is kept next to the session cache.
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"claude-manager/internal/sessions"
+)
+
+// indexVersion is bumped whenever the on-disk layout or tokenization changes.
+const indexVersion = 2
+
+// Term length limits. Shorter words are too common to be useful and longer
+// ones are mostly hashes and encoded blobs.
+const (
+	minTermLen = 2
+	maxTermLen = 40
+)
+
+// Hit is a message that matched a search.
+type Hit struct {
+	File   string // session file path
+	Offset int64  // byte offset of the message's line in File
+	Role   string // "user" or "assistant"
+}
+
+// doc is an indexed message.
+type doc struct {
+	File   uint32 // index into data.Paths
+	Offset int64
+	Role   string
+}
+
+// fileEntry tracks how much of a session file has been indexed.
+type fileEntry struct {
+	ID      uint32
+	Offset  int64     // always just past a newline
+	ModTime time.Time // of the file when last synced
+}
+
+// data is the persisted part of the index.
+type data struct {
+	Version int
+	Files   map[string]*fileEntry
+	Paths   []string            // file path by ID; "" once the file is dropped
+	Docs    []doc               // by doc ID
+	Terms   map[string][]uint32 // term -> ascending doc IDs
+	Dead    int                 // docs whose file was dropped
+}
+
+// Index is an inverted index over session messages. It is safe for
+// concurrent use.
+type Index struct {
+	syncMu sync.Mutex // serializes Sync
+
+	mu     sync.RWMutex
+	path   string
+	d      data
+	sorted []string // sorted terms, for prefix lookups; nil when stale
+	ready  bool
+	dirty  bool
+}
+
+// indexPath returns the index location, e.g. ~/.cache/claude-manager/index.gob
+func indexPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "claude-manager", "index.gob"), nil
+}
+
+// Open loads the index from disk. A missing, unreadable or outdated index
+// yields an empty one that the next Sync fills in.
+func Open() *Index {
+	x := &Index{d: emptyData()}
+	path, err := indexPath()
+	if err != nil {
+		return x
+	}
+	x.path = path
+
+	f, err := os.Open(path)
+	if err != nil {
+		return x
+	}
+	defer f.Close()
+
+	var d data
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(&d); err != nil || d.Version != indexVersion {
+		x.dirty = true
+		return x
+	}
+	if d.Files == nil {
+		d.Files = make(map[string]*fileEntry)
+	}
+	if d.Terms == nil {
+		d.Terms = make(map[string][]uint32)
+	}
+	x.d = d
+	return x
+}
+
+func emptyData() data {
+	return data{
+		Version: indexVersion,
+		Files:   make(map[string]*fileEntry),
+		Terms:   make(map[string][]uint32),
+	}
+}
+
+// Ready reports whether the index has been synced at least once since it
+// was opened, so its answers cover every session file.
+func (x *Index) Ready() bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.ready
+}
+
+// Sync brings the index up to date with the given session files: new bytes
+// are indexed and rewritten files are reindexed. Files not listed are dropped
+// once they no longer exist, so syncing the sessions of one root, or without
+// the archive, keeps the others.
+func (x *Index) Sync(paths []string) error {
+	x.syncMu.Lock()
+	defer x.syncMu.Unlock()
+
+	listed := make(map[string]bool, len(paths))
+	var firstErr error
+	for _, path := range paths {
+		listed[path] = true
+		if err := x.syncFile(path); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	for path := range x.d.Files {
+		if listed[path] {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			x.dropFile(path)
+		}
+	}
+	if x.d.Dead > 10000 && x.d.Dead > len(x.d.Docs)/2 {
+		x.compact()
+	}
+	x.ready = true
+	return firstErr
+}
+
+// newDoc is a message read from a file but not yet added to the index.
+type newDoc struct {
+	offset int64
+	role   string
+	terms  []string
+}
+
+// syncFile indexes whatever was appended to path since the last sync.
+func (x *Index) syncFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	x.mu.RLock()
+	var offset int64
+	var modTime time.Time
+	if fe, ok := x.d.Files[path]; ok {
+		offset, modTime = fe.Offset, fe.ModTime
+	}
+	x.mu.RUnlock()
+	if offset == info.Size() {
+		if modTime.Equal(info.ModTime()) {
+			return nil
+		}
+		// Appends grow the file, so a change that keeps its size is a
+		// rewrite.
+		offset = 0
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if !resumable(f, offset, info.Size()) {
+		offset = 0
+	}
+	docs, end, err := readDocs(f, offset)
+	if err != nil {
+		return err
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	fe, ok := x.d.Files[path]
+	if ok && offset == 0 && fe.Offset > 0 {
+		// The file was rewritten; its old messages are stale.
+		x.dropFile(path)
+		ok = false
+	}
+	if !ok {
+		fe = &fileEntry{ID: uint32(len(x.d.Paths))}
+		x.d.Paths = append(x.d.Paths, path)
+		x.d.Files[path] = fe
+	}
+	for _, nd := range docs {
+		id := uint32(len(x.d.Docs))
+		x.d.Docs = append(x.d.Docs, doc{File: fe.ID, Offset: nd.offset, Role: nd.role})
+		for _, t := range nd.terms {
+			x.d.Terms[t] = append(x.d.Terms[t], id)
+		}
+	}
+	fe.Offset = end
+	fe.ModTime = info.ModTime()
+	x.sorted = nil
+	x.dirty = true
+	return nil
+}
+
+// resumable reports whether indexing can continue at offset: the file must
+// not have shrunk, and offset must still be just past a line.
+func resumable(f *os.File, offset, size int64) bool {
+	if offset == 0 {
+		return true
+	}
+	if size < offset {
+		return false
+	}
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, offset-1); err != nil {
+		return false
+	}
+	return b[0] == '\n'
+}
+
+// readDocs reads complete lines from offset and returns their messages and
+// the offset just past the last complete line.
+func readDocs(f *os.File, offset int64) ([]newDoc, int64, error) {
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	var docs []newDoc
+	r := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			// A partial last line is picked up once it is complete.
+			return docs, offset, nil
+		}
+		if err != nil {
+			return docs, offset, err
+		}
+		if role, text, ok := sessions.EntryText(bytes.TrimSpace(line)); ok {
+			if terms := uniqueTerms(text); len(terms) > 0 {
+				docs = append(docs, newDoc{offset: offset, role: role, terms: terms})
+			}
+		}
+		offset += int64(len(line))
+	}
+}
+
+// dropFile forgets a file. Its docs stay in the postings until the next
+// compaction but no longer match. Callers hold x.mu.
+func (x *Index) dropFile(path string) {
+	fe, ok := x.d.Files[path]
+	if !ok {
+		return
+	}
+	for _, d := range x.d.Docs {
+		if d.File == fe.ID {
+			x.d.Dead++
+		}
+	}
+	x.d.Paths[fe.ID] = ""
+	delete(x.d.Files, path)
+	x.dirty = true
+}
+
+// compact rewrites the docs and postings without dropped files' messages.
+// Callers hold x.mu.
+func (x *Index) compact() {
+	remap := make([]int64, len(x.d.Docs))
+	var docs []doc
+	for i, d := range x.d.Docs {
+		if x.d.Paths[d.File] == "" {
+			remap[i] = -1
+			continue
+		}
+		remap[i] = int64(len(docs))
+		docs = append(docs, d)
+	}
+	for t, ids := range x.d.Terms {
+		kept := ids[:0]
+		for _, id := range ids {
+			if n := remap[id]; n >= 0 {
+				kept = append(kept, uint32(n))
+			}
+		}
+		if len(kept) == 0 {
+			delete(x.d.Terms, t)
+		} else {
+			x.d.Terms[t] = kept
+		}
+	}
+	x.d.Docs = docs
+	x.d.Dead = 0
+	x.sorted = nil
+	x.dirty = true
+}
+
+// Save writes the index to disk atomically if it changed.
+func (x *Index) Save() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if !x.dirty || x.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(x.path), ".index-*.gob")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := gob.NewEncoder(w).Encode(x.d); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), x.path); err != nil {
+		return err
+	}
+	x.dirty = false
+	return nil
+}
+
+// Search returns the messages containing every word of text, in index
+// order. Each word matches any indexed word it is a prefix of; with more
+// than one word, they must also appear together as a phrase. ok is false
+// when text has no word long enough to look up.
+func (x *Index) Search(text string) (hits []Hit, ok bool) {
+	words := terms(text)
+	if len(words) == 0 {
+		return nil, false
+	}
+
+	// Hold the lock throughout, so a sync cannot clear the sorted terms
+	// between building and reading them.
+	x.mu.Lock()
+	if x.sorted == nil {
+		x.sorted = make([]string, 0, len(x.d.Terms))
+		for t := range x.d.Terms {
+			x.sorted = append(x.sorted, t)
+		}
+		sort.Strings(x.sorted)
+	}
+	var ids []uint32
+	for i, w := range words {
+		matched := x.prefixDocs(w)
+		if i == 0 {
+			ids = matched
+		} else {
+			ids = intersect(ids, matched)
+		}
+		if len(ids) == 0 {
+			break
+		}
+	}
+	for _, id := range ids {
+		d := x.d.Docs[id]
+		if path := x.d.Paths[d.File]; path != "" {
+			hits = append(hits, Hit{File: path, Offset: d.Offset, Role: d.Role})
+		}
+	}
+	x.mu.Unlock()
+
+	if phrase := splitWords(text); len(phrase) > 1 {
+		hits = verifyPhrase(hits, phrase)
+	}
+	return hits, true
+}
+
+// Files returns the session files with a message matching text, as Search.
+func (x *Index) Files(text string) (map[string]bool, bool) {
+	hits, ok := x.Search(text)
+	if !ok {
+		return nil, false
+	}
+	files := make(map[string]bool)
+	for _, h := range hits {
+		files[h.File] = true
+	}
+	return files, true
+}
+
+// prefixDocs returns the ascending doc IDs of every term starting with
+// prefix. Callers hold x.mu with x.sorted built.
+func (x *Index) prefixDocs(prefix string) []uint32 {
+	i := sort.SearchStrings(x.sorted, prefix)
+	var lists [][]uint32
+	for ; i < len(x.sorted) && strings.HasPrefix(x.sorted[i], prefix); i++ {
+		lists = append(lists, x.d.Terms[x.sorted[i]])
+	}
+	switch len(lists) {
+	case 0:
+		return nil
+	case 1:
+		return lists[0]
+	}
+	seen := make(map[uint32]bool)
+	var out []uint32
+	for _, l := range lists {
+		for _, id := range l {
+			if !seen[id] {
+				seen[id] = true
+				out = append(out, id)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// intersect returns the IDs present in both ascending lists.
+func intersect(a, b []uint32) []uint32 {
+	var out []uint32
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] < b[j]:
+			i++
+		case a[i] > b[j]:
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// verifyPhrase keeps the hits whose message contains words consecutively,
+// the last one as a prefix. Messages are read back from their files and
+// compared word for word, including words too short to index.
+func verifyPhrase(hits []Hit, words []string) []Hit {
+	files := make(map[string]*os.File)
+	defer func() {
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+
+	var out []Hit
+	for _, h := range hits {
+		f, ok := files[h.File]
+		if !ok {
+			var err error
+			if f, err = os.Open(h.File); err != nil {
+				continue
+			}
+			files[h.File] = f
+		}
+		line, err := bufio.NewReader(io.NewSectionReader(f, h.Offset, 1<<62)).ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			continue
+		}
+		if _, text, ok := sessions.EntryText(bytes.TrimSpace(line)); ok && containsPhrase(splitWords(text), words) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+func containsPhrase(haystack, words []string) bool {
+	last := len(words) - 1
+	for i := 0; i+len(words) <= len(haystack); i++ {
+		match := true
+		for j, w := range words {
+			if j == last && strings.HasPrefix(haystack[i+j], w) {
+				continue
+			}
+			if haystack[i+j] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// splitWords splits text into lowercase words of letters and digits, in
+// order.
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// terms returns the words of text, dropping those too short or too long to
+// index.
+func terms(text string) []string {
+	var out []string
+	for _, w := range splitWords(text) {
+		if n := len(w); n >= minTermLen && n <= maxTermLen {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// uniqueTerms returns the distinct terms of text.
+func uniqueTerms(text string) []string {
+	all := terms(text)
+	seen := make(map[string]bool, len(all))
+	out := all[:0]
+	for _, t := range all {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
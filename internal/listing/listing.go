@@ -0,0 +1,345 @@
+// Package listing writes sessions as tables or machine-readable records for
+// `claude-manager list`.
+package listing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"claude-manager/internal/pricing"
+	"claude-manager/internal/sessions"
+)
+
+// Field is a selectable column of the listing.
+type Field struct {
+	Name string
+	// Value returns the field's machine-readable value: a string, int,
+	// int64, float64, bool, time.Time or []string.
+	Value func(s sessions.Session, prices pricing.Table) any
+	// Human optionally renders the field for the text table.
+	Human func(s sessions.Session, prices pricing.Table) string
+}
+
+// Fields lists every available field in display order.
+var Fields = []Field{
+	{Name: "id", Value: func(s sessions.Session, _ pricing.Table) any { return s.ID }},
+	{Name: "project", Value: func(s sessions.Session, _ pricing.Table) any { return s.Project }},
+	{Name: "project_path", Value: func(s sessions.Session, _ pricing.Table) any { return s.ProjectPath }},
+	{
+		Name:  "summary",
+		Value: func(s sessions.Session, _ pricing.Table) any { return s.Summary },
+		Human: func(s sessions.Session, _ pricing.Table) string { return sessions.Truncate(s.Summary, 60) },
+	},
+	{Name: "title", Value: func(s sessions.Session, _ pricing.Table) any { return s.Title }},
+	{Name: "original_summary", Value: func(s sessions.Session, _ pricing.Table) any { return originalSummary(s) }},
+	{Name: "branch", Value: func(s sessions.Session, _ pricing.Table) any { return s.GitBranch }},
+	{
+		Name:  "created",
+		Value: func(s sessions.Session, _ pricing.Table) any { return s.Created },
+		Human: func(s sessions.Session, _ pricing.Table) string { return s.Created.Local().Format("Jan 2 15:04") },
+	},
+	{
+		Name:  "last_active",
+		Value: func(s sessions.Session, _ pricing.Table) any { return s.LastActive },
+		Human: func(s sessions.Session, _ pricing.Table) string { return s.TimeAgo() },
+	},
+	{Name: "messages", Value: func(s sessions.Session, _ pricing.Table) any { return s.MessageCount }},
+	{
+		Name:  "models",
+		Value: func(s sessions.Session, _ pricing.Table) any { return models(s) },
+		Human: func(s sessions.Session, _ pricing.Table) string { return strings.Join(models(s), ",") },
+	},
+	{Name: "input_tokens", Value: func(s sessions.Session, _ pricing.Table) any { return allTokens(s).Input }},
+	{Name: "output_tokens", Value: func(s sessions.Session, _ pricing.Table) any { return allTokens(s).Output }},
+	{Name: "cache_creation_tokens", Value: func(s sessions.Session, _ pricing.Table) any { return allTokens(s).CacheCreation }},
+	{Name: "cache_read_tokens", Value: func(s sessions.Session, _ pricing.Table) any { return allTokens(s).CacheRead }},
+	{
+		Name:  "tokens",
+		Value: func(s sessions.Session, _ pricing.Table) any { return allTokens(s).Total() },
+		Human: func(s sessions.Session, _ pricing.Table) string { return sessions.FormatTokens(allTokens(s).Total()) },
+	},
+	{
+		Name:  "cost",
+		Value: func(s sessions.Session, p pricing.Table) any { return math.Round(p.Cost(s.AllUsage())*1e6) / 1e6 },
+		Human: func(s sessions.Session, p pricing.Table) string { return pricing.FormatUSD(p.Cost(s.AllUsage())) },
+	},
+	{Name: "file", Value: func(s sessions.Session, _ pricing.Table) any { return s.FilePath }},
+	{Name: "continues", Value: func(s sessions.Session, _ pricing.Table) any { return s.Continues }},
+	{
+		Name:  "chain",
+		Value: func(s sessions.Session, _ pricing.Table) any { return append([]string{}, s.Chain...) },
+		Human: func(s sessions.Session, _ pricing.Table) string { return strings.Join(s.Chain, ",") },
+	},
+	{Name: "archived", Value: func(s sessions.Session, _ pricing.Table) any { return s.Archived }},
+	{Name: "root", Value: func(s sessions.Session, _ pricing.Table) any { return s.Root }},
+	{Name: "config_dir", Value: func(s sessions.Session, _ pricing.Table) any { return s.ConfigDir }},
+	{
+		Name:  "tags",
+		Value: func(s sessions.Session, _ pricing.Table) any { return append([]string{}, s.Tags...) },
+		Human: func(s sessions.Session, _ pricing.Table) string { return formatTags(s.Tags) },
+	},
+	{Name: "pinned", Value: func(s sessions.Session, _ pricing.Table) any { return s.Pinned }},
+	{Name: "note", Value: func(s sessions.Session, _ pricing.Table) any { return s.Note }},
+	{Name: "message_text", Value: func(s sessions.Session, _ pricing.Table) any { return userText(s) }},
+}
+
+// DefaultTableFields are the columns of the plain `list` table.
+var DefaultTableFields = []string{"project", "summary", "branch", "last_active", "tokens", "cost", "id"}
+
+// DefaultRecordFields are the fields written by the machine-readable formats
+// when --fields is not given.
+var DefaultRecordFields = []string{"id", "project", "project_path", "summary", "branch", "created", "last_active", "messages", "models", "tokens", "cost", "file"}
+
+// Formats lists the supported output formats.
+var Formats = []string{"table", "json", "jsonl", "csv", "tsv"}
+
+// ParseFields resolves a comma-separated field list.
+func ParseFields(list string) ([]Field, error) {
+	byName := make(map[string]Field, len(Fields))
+	for _, f := range Fields {
+		byName[f.Name] = f
+	}
+	var out []Field
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		f, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q (available: %s)", name, strings.Join(fieldNames(Fields), ", "))
+		}
+		out = append(out, f)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no fields selected")
+	}
+	return out, nil
+}
+
+// Write writes sessions in the given format using the selected fields.
+func Write(w io.Writer, ss []sessions.Session, fields []Field, format string, prices pricing.Table) error {
+	switch format {
+	case "table":
+		return writeTable(w, ss, fields, prices)
+	case "json":
+		return writeJSON(w, ss, fields, prices)
+	case "jsonl":
+		return writeJSONL(w, ss, fields, prices)
+	case "csv":
+		return writeCSV(w, ss, fields, prices)
+	case "tsv":
+		return writeTSV(w, ss, fields, prices)
+	}
+	return fmt.Errorf("unknown format %q (want %s)", format, strings.Join(Formats, ", "))
+}
+
+func writeTable(w io.Writer, ss []sessions.Session, fields []Field, prices pricing.Table) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	headers := make([]string, len(fields))
+	for i, f := range fields {
+		headers[i] = strings.ToUpper(strings.ReplaceAll(f.Name, "_", " "))
+		if f.Name == "id" {
+			headers[i] = "SESSION ID"
+		}
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, s := range ss {
+		cells := make([]string, len(fields))
+		for i, f := range fields {
+			if f.Human != nil {
+				cells[i] = f.Human(s, prices)
+			} else {
+				cells[i] = flatten(text(f.Value(s, prices)))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, ss []sessions.Session, fields []Field, prices pricing.Table) error {
+	if len(ss) == 0 {
+		_, err := io.WriteString(w, "[]\n")
+		return err
+	}
+	if _, err := io.WriteString(w, "[\n"); err != nil {
+		return err
+	}
+	for i, s := range ss {
+		obj, err := record(s, fields, prices)
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if i == len(ss)-1 {
+			sep = "\n"
+		}
+		if _, err := fmt.Fprintf(w, "  %s%s", obj, sep); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
+
+func writeJSONL(w io.Writer, ss []sessions.Session, fields []Field, prices pricing.Table) error {
+	for _, s := range ss {
+		obj, err := record(s, fields, prices)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s\n", obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record encodes a session as a JSON object with keys in field order.
+func record(s sessions.Session, fields []Field, prices pricing.Table) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		v := f.Value(s, prices)
+		if t, ok := v.(time.Time); ok {
+			v = formatTime(t)
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func writeCSV(w io.Writer, ss []sessions.Session, fields []Field, prices pricing.Table) error {
+	cw := csv.NewWriter(w)
+	cw.Write(fieldNames(fields))
+	for _, s := range ss {
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = text(f.Value(s, prices))
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTSV writes unquoted tab-separated values. Tabs and newlines inside
+// values are collapsed to spaces so every record stays on one line.
+func writeTSV(w io.Writer, ss []sessions.Session, fields []Field, prices pricing.Table) error {
+	if _, err := fmt.Fprintln(w, strings.Join(fieldNames(fields), "\t")); err != nil {
+		return err
+	}
+	for _, s := range ss {
+		row := make([]string, len(fields))
+		for i, f := range fields {
+			row[i] = flatten(text(f.Value(s, prices)))
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fieldNames(fields []Field) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// text renders a field value as plain text.
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 4, 64)
+	case time.Time:
+		return formatTime(v)
+	case []string:
+		return strings.Join(v, ",")
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// flatten collapses whitespace, including tabs and newlines, to single spaces.
+func flatten(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// userText returns the session's user prompts, read from its file as they
+// are not kept in memory.
+func userText(s sessions.Session) string {
+	text, _ := sessions.UserText(s.FilePath)
+	return text
+}
+
+// originalSummary returns the summary derived from the session itself,
+// ignoring any custom title.
+func originalSummary(s sessions.Session) string {
+	if s.Title != "" {
+		return s.OriginalSummary
+	}
+	return s.Summary
+}
+
+// formatTags renders tags as "#a #b".
+func formatTags(tags []string) string {
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = "#" + t
+	}
+	return strings.Join(out, " ")
+}
+
+func models(s sessions.Session) []string {
+	usage := s.AllUsage()
+	out := make([]string, 0, len(usage))
+	for m := range usage {
+		out = append(out, m)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// allTokens totals the session's usage, sub-agents included, so token
+// fields describe the same usage as cost.
+func allTokens(s sessions.Session) sessions.TokenUsage {
+	return sessions.SumUsage(s.AllUsage())
+}
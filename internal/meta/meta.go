@@ -0,0 +1,176 @@
+// Package meta stores claude-manager's own per-session data — tags, pins and
+// notes — in a sidecar file keyed by session ID. Claude's JSONL files are
+// never modified.
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"claude-manager/internal/config"
+	"claude-manager/internal/sessions"
+)
+
+// Entry is the user data attached to one session.
+type Entry struct {
+	Title  string   `json:"title,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Pinned bool     `json:"pinned,omitempty"`
+	Note   string   `json:"note,omitempty"`
+}
+
+func (e Entry) empty() bool {
+	return e.Title == "" && len(e.Tags) == 0 && !e.Pinned && e.Note == ""
+}
+
+// Store holds every session's Entry and the file they are saved to.
+type Store struct {
+	path    string
+	entries map[string]Entry
+}
+
+type storeFile struct {
+	Sessions map[string]Entry `json:"sessions"`
+}
+
+// Path returns the sidecar file location, e.g. ~/.config/claude-manager/sessions.json
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions.json"), nil
+}
+
+// Open reads the sidecar file. A missing file yields an empty Store; an
+// unreadable one is an error so that saving cannot clobber it.
+func Open() (*Store, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	s := &Store{path: path, entries: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f storeFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if f.Sessions != nil {
+		s.entries = f.Sessions
+	}
+	return s, nil
+}
+
+// Get returns the entry for a session ID.
+func (s *Store) Get(id string) Entry {
+	return s.entries[id]
+}
+
+// Set replaces the entry for a session ID, dropping it when empty.
+func (s *Store) Set(id string, e Entry) {
+	e.Title = strings.Join(strings.Fields(e.Title), " ")
+	e.Tags = normalizeTags(e.Tags)
+	e.Note = strings.TrimSpace(e.Note)
+	if e.empty() {
+		delete(s.entries, id)
+		return
+	}
+	s.entries[id] = e
+}
+
+// Apply copies each session's entry onto its Tags, Pinned and Note fields,
+// and replaces Summary with a custom title if there is one. It can be called
+// again on the same sessions after the store changes.
+func (s *Store) Apply(ss []sessions.Session) {
+	for i := range ss {
+		e := s.entries[ss[i].ID]
+		if ss[i].Title != "" {
+			ss[i].Summary = ss[i].OriginalSummary
+		}
+		ss[i].Title, ss[i].OriginalSummary = "", ""
+		if e.Title != "" {
+			ss[i].Title = e.Title
+			ss[i].OriginalSummary = ss[i].Summary
+			ss[i].Summary = e.Title
+		}
+		ss[i].Tags = e.Tags
+		ss[i].Pinned = e.Pinned
+		ss[i].Note = e.Note
+	}
+}
+
+// Save writes the store back to disk atomically.
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(storeFile{Sessions: s.entries}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".sessions-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// NormalizeTag lowercases a tag and strips a leading '#'.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// EditTags applies a tag edit to existing tags. Words prefixed with '-'
+// remove a tag; all others, optionally prefixed with '+', add one.
+func EditTags(tags []string, edit string) []string {
+	out := append([]string(nil), tags...)
+	for _, word := range strings.Fields(edit) {
+		if rest, ok := strings.CutPrefix(word, "-"); ok {
+			rm := NormalizeTag(rest)
+			kept := out[:0]
+			for _, t := range out {
+				if t != rm {
+					kept = append(kept, t)
+				}
+			}
+			out = kept
+			continue
+		}
+		out = append(out, strings.TrimPrefix(word, "+"))
+	}
+	return normalizeTags(out)
+}
+
+// normalizeTags returns the tags normalized, deduplicated and sorted.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, t := range tags {
+		t = NormalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
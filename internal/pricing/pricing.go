@@ -0,0 +1,90 @@
+// Package pricing estimates the dollar cost of Claude token usage.
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"claude-manager/internal/sessions"
+)
+
+// Price is the cost in USD per million tokens for one model family.
+type Price struct {
+	Input      float64 `json:"input"`
+	Output     float64 `json:"output"`
+	CacheWrite float64 `json:"cache_write"`
+	CacheRead  float64 `json:"cache_read"`
+}
+
+// Table maps model name prefixes to prices. A model uses the entry with the
+// longest prefix matching its name, so "claude-sonnet-4-5-20250929" matches
+// "claude-sonnet-4-5" before "claude-sonnet-4".
+type Table map[string]Price
+
+// Default returns the built-in price table (USD per million tokens).
+func Default() Table {
+	return Table{
+		"claude-opus-4-5":   {Input: 5, Output: 25, CacheWrite: 6.25, CacheRead: 0.50},
+		"claude-opus-4":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+		"claude-sonnet-4":   {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+		"claude-haiku-4-5":  {Input: 1, Output: 5, CacheWrite: 1.25, CacheRead: 0.10},
+		"claude-3-7-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+		"claude-3-5-sonnet": {Input: 3, Output: 15, CacheWrite: 3.75, CacheRead: 0.30},
+		"claude-3-5-haiku":  {Input: 0.80, Output: 4, CacheWrite: 1, CacheRead: 0.08},
+		"claude-3-opus":     {Input: 15, Output: 75, CacheWrite: 18.75, CacheRead: 1.50},
+		"claude-3-haiku":    {Input: 0.25, Output: 1.25, CacheWrite: 0.30, CacheRead: 0.03},
+	}
+}
+
+// Merge returns a copy of t with the entries of overrides added or replaced.
+func (t Table) Merge(overrides Table) Table {
+	merged := make(Table, len(t)+len(overrides))
+	for k, v := range t {
+		merged[k] = v
+	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	return merged
+}
+
+// Lookup returns the price for model, matched by longest prefix.
+func (t Table) Lookup(model string) (Price, bool) {
+	keys := make([]string, 0, len(t))
+	for k := range t {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	for _, k := range keys {
+		if strings.HasPrefix(model, k) {
+			return t[k], true
+		}
+	}
+	return Price{}, false
+}
+
+// Cost returns the cost of u at price p.
+func (p Price) Cost(u sessions.TokenUsage) float64 {
+	return (float64(u.Input)*p.Input +
+		float64(u.Output)*p.Output +
+		float64(u.CacheCreation)*p.CacheWrite +
+		float64(u.CacheRead)*p.CacheRead) / 1e6
+}
+
+// Cost estimates the cost of per-model usage. Models missing from the table
+// contribute nothing.
+func (t Table) Cost(usage map[string]sessions.TokenUsage) float64 {
+	var total float64
+	for model, u := range usage {
+		if p, ok := t.Lookup(model); ok {
+			total += p.Cost(u)
+		}
+	}
+	return total
+}
+
+// FormatUSD renders a cost estimate, e.g. "$1.23".
+func FormatUSD(v float64) string {
+	return fmt.Sprintf("$%.2f", v)
+}
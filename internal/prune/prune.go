@@ -0,0 +1,166 @@
+// Package prune selects sessions to clean up according to a retention policy.
+package prune
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"claude-manager/internal/sessions"
+)
+
+// Policy describes which sessions to prune. A session is pruned if it matches
+// any enabled rule; zero values disable a rule.
+type Policy struct {
+	OlderThan   time.Time // last active before this
+	MaxMessages int       // at most this many messages
+	MissingPath bool      // project path no longer exists on disk
+	Keep        int       // keep only the newest N sessions per project
+}
+
+// Empty reports whether no rule is enabled.
+func (p Policy) Empty() bool {
+	return p.OlderThan.IsZero() && p.MaxMessages == 0 && !p.MissingPath && p.Keep == 0
+}
+
+// Candidate is a session selected for pruning.
+type Candidate struct {
+	Session sessions.Session
+	Reasons []string
+	Size    int64 // bytes on disk, including Claude's per-session directory
+}
+
+// Plan returns the sessions the policy would prune, in the order given.
+// A continuation chain is judged as one session, with the messages of all its
+// parts and the last activity of its latest, and is pruned or kept whole.
+// Keep ranks each project's sessions by last activity, whatever the order of
+// ss. Pinned sessions, and chains with a pinned part, are never pruned but
+// still count towards Keep.
+func Plan(ss []sessions.Session, p Policy) []Candidate {
+	missing := make(map[string]bool)
+	units := chainUnits(ss)
+	rank := keepRanks(units)
+
+	pruned := make(map[string][]string) // reasons by chain ID
+	for id, u := range units {
+		var reasons []string
+
+		if !p.OlderThan.IsZero() && u.latest.LastActive.Before(p.OlderThan) {
+			reasons = append(reasons, "old")
+		}
+		if p.MaxMessages > 0 && u.messages <= p.MaxMessages {
+			reasons = append(reasons, fmt.Sprintf("%d msgs", u.messages))
+		}
+		if path := u.latest.ProjectPath; p.MissingPath && path != "" {
+			gone, ok := missing[path]
+			if !ok {
+				_, err := os.Stat(path)
+				gone = os.IsNotExist(err)
+				missing[path] = gone
+			}
+			if gone {
+				reasons = append(reasons, "path gone")
+			}
+		}
+		if p.Keep > 0 && rank[id] > p.Keep {
+			reasons = append(reasons, fmt.Sprintf("over keep %d", p.Keep))
+		}
+
+		if len(reasons) > 0 && !u.pinned {
+			pruned[id] = reasons
+		}
+	}
+
+	var out []Candidate
+	for _, s := range ss {
+		if reasons := pruned[s.ChainID()]; len(reasons) > 0 {
+			out = append(out, Candidate{Session: s, Reasons: reasons, Size: diskSize(s.FilePath)})
+		}
+	}
+	return out
+}
+
+// unit is a session, or a continuation chain taken as a whole.
+type unit struct {
+	latest   sessions.Session // most recently active part
+	messages int              // of all parts
+	pinned   bool             // any part is pinned
+}
+
+// chainUnits groups ss into units by chain ID.
+func chainUnits(ss []sessions.Session) map[string]*unit {
+	units := make(map[string]*unit)
+	for _, s := range ss {
+		u, ok := units[s.ChainID()]
+		if !ok {
+			u = &unit{latest: s}
+			units[s.ChainID()] = u
+		} else if s.LastActive.After(u.latest.LastActive) {
+			u.latest = s
+		}
+		u.messages += s.MessageCount
+		u.pinned = u.pinned || s.Pinned
+	}
+	return units
+}
+
+// keepRanks returns, by chain ID, each unit's position among the units of
+// its project ordered most recently active first, from 1.
+func keepRanks(units map[string]*unit) map[string]int {
+	ids := make([]string, 0, len(units))
+	for id := range units {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := units[ids[i]].latest, units[ids[j]].latest
+		if !a.LastActive.Equal(b.LastActive) {
+			return a.LastActive.After(b.LastActive)
+		}
+		return ids[i] < ids[j]
+	})
+	perProject := make(map[string]int)
+	rank := make(map[string]int, len(ids))
+	for _, id := range ids {
+		s := units[id].latest
+		key := s.ProjectPath
+		if key == "" {
+			key = s.Project
+		}
+		perProject[key]++
+		rank[id] = perProject[key]
+	}
+	return rank
+}
+
+// diskSize returns the size of a session file plus the directory Claude may
+// keep beside it for sub-agent transcripts and tool results.
+func diskSize(path string) int64 {
+	var total int64
+	if info, err := os.Stat(path); err == nil {
+		total += info.Size()
+	}
+	companion := path[:len(path)-len(filepath.Ext(path))]
+	filepath.Walk(companion, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+// FormatBytes renders a byte count, e.g. 512 B, 3.4 MB, 1.2 GB.
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
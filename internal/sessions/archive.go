@@ -0,0 +1,66 @@
+package sessions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Archive moves a session's JSONL file, and the per-session directory Claude
+// keeps beside it if there is one, from its root's projects directory into
+// the root's archive. It returns the archived file path.
+func Archive(s Session) (string, error) {
+	if s.Archived {
+		return "", fmt.Errorf("session %s is already archived", s.ID)
+	}
+	root, err := sessionRoot(s)
+	if err != nil {
+		return "", err
+	}
+	return moveSession(s.FilePath, root.ArchiveDir())
+}
+
+// Restore moves an archived session back into its root's projects directory
+// and returns its restored file path.
+func Restore(s Session) (string, error) {
+	if !s.Archived {
+		return "", fmt.Errorf("session %s is not archived", s.ID)
+	}
+	root, err := sessionRoot(s)
+	if err != nil {
+		return "", err
+	}
+	return moveSession(s.FilePath, root.ProjectsDir())
+}
+
+// moveSession moves <root>/<project>/<file>.jsonl to <dest>/<project>/<file>.jsonl,
+// along with a sibling <root>/<project>/<file>/ directory if present.
+func moveSession(path, destRoot string) (string, error) {
+	projectDir := filepath.Base(filepath.Dir(path))
+	destDir := filepath.Join(destRoot, projectDir)
+	dest := filepath.Join(destDir, filepath.Base(path))
+
+	if _, err := os.Lstat(dest); err == nil {
+		return "", fmt.Errorf("%s already exists", dest)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", err
+	}
+
+	// Newer Claude Code versions keep sub-agent transcripts and large tool
+	// results in a directory named after the session.
+	companion := path[:len(path)-len(filepath.Ext(path))]
+	if info, err := os.Stat(companion); err == nil && info.IsDir() {
+		destCompanion := dest[:len(dest)-len(filepath.Ext(dest))]
+		if err := os.Rename(companion, destCompanion); err != nil {
+			return dest, fmt.Errorf("moved %s but not %s: %w", filepath.Base(path), companion, err)
+		}
+	}
+
+	// Drop the source project directory if archiving emptied it.
+	os.Remove(filepath.Dir(path))
+	return dest, nil
+}
@@ -0,0 +1,159 @@
+package sessions
+
+import (
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// cacheVersion is bumped whenever the cached Session layout changes so that
+// stale caches are discarded instead of decoded into the wrong shape.
+const cacheVersion = 12
+
+// cacheEntry is the cached parse state for one session file.
+type cacheEntry struct {
+	Size    int64
+	ModTime time.Time
+	State   *parseState
+}
+
+type cacheFile struct {
+	Version int
+	Entries map[string]cacheEntry
+}
+
+// cache is an on-disk index of parsed session files keyed by file path.
+// Entries are reused as-is while the file's size and mtime are unchanged, and
+// resumed from their saved offset when the file has grown.
+type cache struct {
+	path    string
+	entries map[string]cacheEntry
+	seen    map[string]bool
+	dirty   bool
+}
+
+// cachePath returns the path to the session cache, e.g. ~/.cache/claude-manager/sessions.gob
+func cachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "claude-manager", "sessions.gob"), nil
+}
+
+// openCache loads the session cache from disk. A missing, unreadable or
+// outdated cache yields an empty one, so callers never have to handle errors.
+func openCache() *cache {
+	c := &cache{
+		entries: make(map[string]cacheEntry),
+		seen:    make(map[string]bool),
+	}
+
+	path, err := cachePath()
+	if err != nil {
+		return c
+	}
+	c.path = path
+
+	f, err := os.Open(path)
+	if err != nil {
+		return c
+	}
+	defer f.Close()
+
+	var cf cacheFile
+	if err := gob.NewDecoder(f).Decode(&cf); err != nil || cf.Version != cacheVersion {
+		c.dirty = true
+		return c
+	}
+	if cf.Entries != nil {
+		c.entries = cf.Entries
+	}
+	return c
+}
+
+// lookup returns the cached parse state for path, or nil if there is none.
+// fresh reports whether the file is unchanged since the state was stored;
+// otherwise the state can be resumed to pick up appended lines.
+func (c *cache) lookup(path string, info os.FileInfo) (st *parseState, fresh bool) {
+	c.seen[path] = true
+	e, ok := c.entries[path]
+	if !ok {
+		return nil, false
+	}
+	return e.State, e.Size == info.Size() && e.ModTime.Equal(info.ModTime())
+}
+
+// store records the parse state for path as of the given file info.
+func (c *cache) store(path string, info os.FileInfo, st *parseState) {
+	c.seen[path] = true
+	c.entries[path] = cacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		State:   st,
+	}
+	c.dirty = true
+}
+
+// begin starts a new load pass; entries not looked up or stored before the
+// next prune are treated as deleted.
+func (c *cache) begin() {
+	c.seen = make(map[string]bool)
+}
+
+// prune drops entries for files under dirs that were not seen during this
+// pass and returns their paths. Entries for directories not scanned, such as
+// other roots or the archive, are kept.
+func (c *cache) prune(dirs []string) []string {
+	var removed []string
+	for path := range c.entries {
+		if !c.seen[path] && under(path, dirs) {
+			delete(c.entries, path)
+			removed = append(removed, path)
+			c.dirty = true
+		}
+	}
+	return removed
+}
+
+// under reports whether path lies inside one of dirs.
+func under(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// save writes the cache back to disk atomically if it changed.
+func (c *cache) save() error {
+	if !c.dirty || c.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".sessions-*.gob")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	cf := cacheFile{Version: cacheVersion, Entries: c.entries}
+	if err := gob.NewEncoder(tmp).Encode(cf); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
@@ -0,0 +1,183 @@
+package sessions
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// links is what a session file says about the sessions it continues. When a
+// conversation is resumed or compacted, Claude starts a new file that either
+// copies the earlier messages, which keep their session ID and uuids, or
+// opens with an entry whose parent is the earlier file's last entry.
+type links struct {
+	CopiedFrom  []string // other session IDs found on entries of the file
+	FirstUUID   string   // uuid of the first main conversation entry
+	FirstParent string   // parent or logical parent of that entry, if any
+	LastUUID    string   // uuid of the last main conversation entry
+}
+
+// fileSessionID returns the session ID a file is named after, or "" if its
+// name is not a session ID.
+func fileSessionID(path string) string {
+	id := strings.TrimSuffix(filepath.Base(path), ".jsonl")
+	if len(id) != 36 || strings.Count(id, "-") != 4 {
+		return ""
+	}
+	return id
+}
+
+// note records the linking information of a main conversation entry.
+func (l *links) note(e *jsonlEntry) {
+	if e.UUID == "" {
+		return
+	}
+	if l.FirstUUID == "" {
+		l.FirstUUID = e.UUID
+		l.FirstParent = e.ParentUUID
+		if l.FirstParent == "" {
+			l.FirstParent = e.LogicalParentUUID
+		}
+	}
+	l.LastUUID = e.UUID
+}
+
+// copiedFrom records that the file holds a copy of an entry of session id.
+func (l *links) copiedFrom(id string) {
+	for _, c := range l.CopiedFrom {
+		if c == id {
+			return
+		}
+	}
+	l.CopiedFrom = append(l.CopiedFrom, id)
+}
+
+// linkChains sets Continues and Chain on the sessions of results from the
+// links their files recorded. Sessions only continue ones in the same
+// project directory.
+func linkChains(results []parseResult) {
+	byID := make(map[string]*Session)
+	byLast := make(map[string]*Session)
+	byFirst := make(map[string][]*Session)
+	for _, r := range results {
+		if s := r.session; s != nil {
+			s.Continues, s.Chain = "", nil
+			byID[s.ID] = s
+			if r.links.LastUUID != "" {
+				byLast[entryKey(s, r.links.LastUUID)] = s
+			}
+			if r.links.FirstUUID != "" {
+				k := entryKey(s, r.links.FirstUUID)
+				byFirst[k] = append(byFirst[k], s)
+			}
+		}
+	}
+
+	for _, r := range results {
+		s := r.session
+		if s == nil {
+			continue
+		}
+		var prev *Session
+		// Copied entries may come from several generations; the session
+		// continued is the most recent of them.
+		for _, id := range r.links.CopiedFrom {
+			if p := byID[id]; p != nil && p != s && sameDir(p, s) && (prev == nil || p.LastActive.After(prev.LastActive)) {
+				prev = p
+			}
+		}
+		if prev == nil && r.links.FirstParent != "" {
+			if p := byLast[entryKey(s, r.links.FirstParent)]; p != s {
+				prev = p
+			}
+		}
+		if prev == nil {
+			for _, p := range byFirst[entryKey(s, r.links.FirstUUID)] {
+				if p != s && p.Created.Before(s.Created) && (prev == nil || p.Created.After(prev.Created)) {
+					prev = p
+				}
+			}
+		}
+		if prev != nil {
+			s.Continues = prev.ID
+		}
+	}
+
+	// Group linked sessions; a session resumed twice splits into two
+	// continuations, which still belong to one chain.
+	parent := make(map[string]string)
+	var find func(id string) string
+	find = func(id string) string {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		root := find(p)
+		parent[id] = root
+		return root
+	}
+	for id, s := range byID {
+		if s.Continues != "" && byID[s.Continues] != nil {
+			a, b := find(id), find(s.Continues)
+			if a != b {
+				parent[a] = b
+			}
+		}
+	}
+	members := make(map[string][]*Session)
+	for id, s := range byID {
+		root := find(id)
+		members[root] = append(members[root], s)
+	}
+	for _, ss := range members {
+		if len(ss) < 2 {
+			continue
+		}
+		sort.Slice(ss, func(i, j int) bool {
+			if !ss[i].Created.Equal(ss[j].Created) {
+				return ss[i].Created.Before(ss[j].Created)
+			}
+			return ss[i].ID < ss[j].ID
+		})
+		chain := make([]string, len(ss))
+		for i, s := range ss {
+			chain[i] = s.ID
+		}
+		for _, s := range ss {
+			s.Chain = chain
+		}
+	}
+}
+
+// entryKey identifies an entry uuid within the project directory of s.
+func entryKey(s *Session, uuid string) string {
+	return filepath.Dir(s.FilePath) + "\x00" + uuid
+}
+
+func sameDir(a, b *Session) bool {
+	return filepath.Dir(a.FilePath) == filepath.Dir(b.FilePath)
+}
+
+// ChainID identifies the chain a session belongs to: the ID of its first
+// part, or the session's own ID if it stands alone.
+func (s Session) ChainID() string {
+	if len(s.Chain) > 0 {
+		return s.Chain[0]
+	}
+	return s.ID
+}
+
+// LatestPart returns the most recently active part of s's chain among ss,
+// which is the one to resume, or s itself if no other part is more recent.
+func LatestPart(ss []Session, s Session) Session {
+	if len(s.Chain) == 0 {
+		return s
+	}
+	latest := s
+	for _, o := range ss {
+		if o.ChainID() == s.ChainID() && o.LastActive.After(latest.LastActive) {
+			latest = o
+		}
+	}
+	return latest
+}
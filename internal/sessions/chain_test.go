@@ -0,0 +1,167 @@
+package sessions
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	idA = "aaaaaaaa-0000-0000-0000-000000000001"
+	idB = "bbbbbbbb-0000-0000-0000-000000000002"
+	idC = "cccccccc-0000-0000-0000-000000000003"
+	idD = "dddddddd-0000-0000-0000-000000000004"
+)
+
+func userEntry(session, uuid, parent, ts, text string) map[string]any {
+	return map[string]any{
+		"type": "user", "sessionId": session, "uuid": uuid, "parentUuid": parent,
+		"cwd": "/nonexistent/api", "timestamp": ts,
+		"message": map[string]any{"role": "user", "content": text},
+	}
+}
+
+func assistantEntry(session, uuid, parent, ts, text, msgID string) map[string]any {
+	return map[string]any{
+		"type": "assistant", "sessionId": session, "uuid": uuid, "parentUuid": parent,
+		"cwd": "/nonexistent/api", "timestamp": ts,
+		"message": map[string]any{
+			"id": msgID, "role": "assistant", "model": "claude-sonnet-4-5",
+			"content": []map[string]any{{"type": "text", "text": text}},
+			"usage":   map[string]any{"input_tokens": 100, "output_tokens": 10},
+		},
+	}
+}
+
+func writeSession(t *testing.T, dir, id string, entries ...map[string]any) {
+	t.Helper()
+	var b strings.Builder
+	for _, e := range entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	if err := os.WriteFile(filepath.Join(dir, id+".jsonl"), []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadChainFixture writes a three-part chain and a standalone session into a
+// temporary root and loads them. A is resumed as B, which copies A's
+// messages; B is then compacted into C, which links back through
+// logicalParentUuid. D has nothing to do with them.
+func loadChainFixture(t *testing.T) map[string]Session {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	root := t.TempDir()
+	dir := filepath.Join(root, "projects", "-nonexistent-api")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	a := []map[string]any{
+		userEntry(idA, "a1", "", "2026-10-01T10:00:00Z", "Add rate limiting"),
+		assistantEntry(idA, "a2", "a1", "2026-10-01T10:01:00Z", "Done.", "ma1"),
+	}
+	writeSession(t, dir, idA, a...)
+	writeSession(t, dir, idB, append(a,
+		userEntry(idB, "b1", "a2", "2026-10-02T10:00:00Z", "Now add tests"),
+		assistantEntry(idB, "b2", "b1", "2026-10-02T10:01:00Z", "Added.", "mb1"),
+	)...)
+	writeSession(t, dir, idC,
+		map[string]any{
+			"type": "system", "subtype": "compact_boundary", "sessionId": idC,
+			"uuid": "c0", "logicalParentUuid": "b2", "timestamp": "2026-10-03T10:00:00Z",
+			"content": "Conversation compacted",
+		},
+		userEntry(idC, "c1", "c0", "2026-10-03T10:01:00Z", "Run the tests"),
+		assistantEntry(idC, "c2", "c1", "2026-10-03T10:02:00Z", "All pass.", "mc1"),
+	)
+	writeSession(t, dir, idD,
+		userEntry(idD, "d1", "", "2026-10-04T10:00:00Z", "Unrelated"),
+		assistantEntry(idD, "d2", "d1", "2026-10-04T10:01:00Z", "Sure.", "md1"),
+	)
+
+	all, fileErrs, err := LoadAll(Root{Name: "test", Dir: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fileErrs) > 0 {
+		t.Fatal(fileErrs)
+	}
+	byID := make(map[string]Session)
+	for _, s := range all {
+		byID[s.ID] = s
+	}
+	if len(byID) != 4 {
+		t.Fatalf("loaded %d sessions, want 4", len(byID))
+	}
+	return byID
+}
+
+func TestLinkChains(t *testing.T) {
+	byID := loadChainFixture(t)
+
+	continues := map[string]string{idA: "", idB: idA, idC: idB, idD: ""}
+	for id, want := range continues {
+		if got := byID[id].Continues; got != want {
+			t.Errorf("%s continues %q, want %q", id[:4], got, want)
+		}
+	}
+
+	chain := []string{idA, idB, idC}
+	for _, id := range chain {
+		s := byID[id]
+		if !reflect.DeepEqual(s.Chain, chain) {
+			t.Errorf("%s chain %v, want %v", id[:4], s.Chain, chain)
+		}
+		if s.ChainID() != idA {
+			t.Errorf("%s chain ID %q, want %q", id[:4], s.ChainID(), idA)
+		}
+	}
+	if d := byID[idD]; d.Chain != nil || d.ChainID() != idD {
+		t.Errorf("standalone session has chain %v and chain ID %q", d.Chain, d.ChainID())
+	}
+}
+
+func TestLatestPart(t *testing.T) {
+	byID := loadChainFixture(t)
+	all := []Session{byID[idA], byID[idB], byID[idC], byID[idD]}
+
+	for _, id := range []string{idA, idB, idC} {
+		if got := LatestPart(all, byID[id]).ID; got != idC {
+			t.Errorf("latest part for %s is %s, want %s", id[:4], got[:4], idC[:4])
+		}
+	}
+	if got := LatestPart(all, byID[idD]).ID; got != idD {
+		t.Errorf("latest part for a standalone session is %s, want itself", got[:4])
+	}
+}
+
+func TestCopiedHistoryIsSkipped(t *testing.T) {
+	byID := loadChainFixture(t)
+
+	b := byID[idB]
+	if b.MessageCount != 2 {
+		t.Errorf("resumed session counts %d messages, want 2 of its own", b.MessageCount)
+	}
+	if !strings.HasPrefix(b.Summary, "Now add tests") {
+		t.Errorf("resumed session summary %q, want its own first prompt", b.Summary)
+	}
+	if got := b.Created.Format("2006-01-02"); got != "2026-10-02" {
+		t.Errorf("resumed session created %s, want 2026-10-02", got)
+	}
+	var out int64
+	for _, u := range b.Usage {
+		out += u.Output
+	}
+	if out != 10 {
+		t.Errorf("resumed session has %d output tokens, want 10 from its own reply", out)
+	}
+}
@@ -0,0 +1,365 @@
+package sessions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileError records a session file that could not be loaded.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// Update describes what changed on disk between two loads.
+type Update struct {
+	Changed []Session // sessions whose files are new or were modified
+	Removed []string  // file paths of sessions whose files disappeared
+}
+
+// Loader loads sessions through the on-disk cache and keeps that cache in
+// memory, so repeated loads only touch the files that changed in between.
+// It is safe for concurrent use.
+type Loader struct {
+	// Roots are the data directories to load from; DefaultRoots if empty.
+	Roots []Root
+	// Archived includes sessions that were moved to the archive.
+	Archived bool
+
+	mu       sync.Mutex
+	cache    *cache
+	projects map[string]projectInfo // resolved project directories, by encoded name
+	shown    map[string]projectInfo // what the last scan reported for each
+	chains   map[string]string      // by file path, the chain links the last scan reported
+}
+
+// NewLoader returns a Loader backed by the on-disk session cache.
+func NewLoader() *Loader {
+	return &Loader{
+		cache:    openCache(),
+		projects: make(map[string]projectInfo),
+		shown:    make(map[string]projectInfo),
+	}
+}
+
+// LoadAll discovers and parses all session files under roots, or under
+// DefaultRoots if none are given. Files whose size and modification time
+// match the on-disk cache are not reparsed, files that grew only have their
+// new lines decoded, and the rest are parsed concurrently. Files that fail
+// to load are returned as FileErrors alongside the sessions that did load.
+func LoadAll(roots ...Root) ([]Session, []FileError, error) {
+	l := NewLoader()
+	l.Roots = roots
+	return l.Load()
+}
+
+// Load returns every session, sorted by most recently active, and writes the
+// cache back to disk.
+func (l *Loader) Load() ([]Session, []FileError, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	all, _, fileErrs, err := l.scan()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The cache is an optimization; failing to write it must not fail the load.
+	l.cache.save()
+
+	return all, fileErrs, nil
+}
+
+// Refresh rescans the session files and reports only what changed since the
+// previous Load or Refresh. The cache is kept in memory; call Save to persist it.
+func (l *Loader) Refresh() (Update, []FileError, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, upd, fileErrs, err := l.scan()
+	return upd, fileErrs, err
+}
+
+// Save writes the in-memory cache to disk.
+func (l *Loader) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.cache.save()
+}
+
+// parseJob is a session file waiting to be parsed by the worker pool. state
+// is either a cached state to resume or a fresh one.
+type parseJob struct {
+	idx   int
+	state *parseState
+	info  os.FileInfo
+}
+
+type parseResult struct {
+	session    *Session
+	root       Root
+	archived   bool
+	projectDir string // encoded name of the project directory
+	links      links
+	err        error
+}
+
+// scanRoot is a directory laid out like ~/.claude/projects.
+type scanRoot struct {
+	root     Root
+	dir      string
+	archived bool
+}
+
+// scanRoots returns the directories to scan: each root's projects directory
+// and, if enabled, its archive. Roots without a readable projects directory
+// are skipped and reported, but it is an error if none has one.
+func (l *Loader) scanRoots() ([]scanRoot, []FileError, error) {
+	roots := l.Roots
+	if len(roots) == 0 {
+		var err error
+		if roots, err = DefaultRoots(); err != nil {
+			return nil, nil, err
+		}
+	}
+	var dirs []scanRoot
+	var skipped []FileError
+	for _, r := range roots {
+		if _, err := os.ReadDir(r.ProjectsDir()); err != nil {
+			skipped = append(skipped, FileError{Path: r.ProjectsDir(), Err: err})
+			continue
+		}
+		dirs = append(dirs, scanRoot{root: r, dir: r.ProjectsDir()})
+		if l.Archived {
+			dirs = append(dirs, scanRoot{root: r, dir: r.ArchiveDir(), archived: true})
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, nil, skipped[0].Err
+	}
+	return dirs, skipped, nil
+}
+
+// scan walks each root's projects directory (and archive, if enabled),
+// parses new and modified files on the worker pool, and returns all sessions
+// along with what changed.
+func (l *Loader) scan() ([]Session, Update, []FileError, error) {
+	var upd Update
+
+	roots, fileErrs, err := l.scanRoots()
+	if err != nil {
+		return nil, upd, nil, err
+	}
+
+	c := l.cache
+	c.begin()
+
+	// Resolve cache hits up front so only changed files reach the workers.
+	var results []parseResult
+	var jobs []parseJob
+	for _, root := range roots {
+		projectDirs, err := os.ReadDir(root.dir)
+		if err != nil {
+			// The archive only exists once something has been archived.
+			continue
+		}
+		for _, pd := range projectDirs {
+			if !pd.IsDir() {
+				continue
+			}
+			projectDir := filepath.Join(root.dir, pd.Name())
+
+			files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
+			if err != nil {
+				fileErrs = append(fileErrs, FileError{Path: projectDir, Err: err})
+				continue
+			}
+
+			for _, f := range files {
+				info, err := os.Stat(f)
+				if err != nil {
+					fileErrs = append(fileErrs, FileError{Path: f, Err: err})
+					continue
+				}
+				idx := len(results)
+				results = append(results, parseResult{root: root.root, archived: root.archived, projectDir: pd.Name()})
+				st, fresh := c.lookup(f, info)
+				if fresh {
+					results[idx].session = st.session()
+					results[idx].links = st.Links
+					continue
+				}
+				if st == nil {
+					st = newParseState(f, pd.Name())
+				}
+				jobs = append(jobs, parseJob{idx: idx, state: st, info: info})
+			}
+		}
+	}
+
+	parseConcurrently(jobs, results)
+	renamed := l.applyProjects(results)
+	relinked := l.applyChains(results)
+
+	for _, r := range results {
+		if r.session != nil {
+			r.session.Archived = r.archived
+			r.session.Root = r.root.Name
+			r.session.ConfigDir = r.root.Dir
+		}
+	}
+
+	parsed := make(map[int]bool, len(jobs))
+	for _, j := range jobs {
+		parsed[j.idx] = true
+		r := results[j.idx]
+		path := j.state.Session.FilePath
+		if r.err != nil {
+			fileErrs = append(fileErrs, FileError{Path: path, Err: r.err})
+			continue
+		}
+		c.store(path, j.info, j.state)
+		if r.session != nil {
+			upd.Changed = append(upd.Changed, *r.session)
+		}
+	}
+	// Cached sessions whose project resolved differently than last time, or
+	// whose chain did, changed too.
+	for i, r := range results {
+		if r.session != nil && !parsed[i] && (renamed[r.projectDir] || relinked[r.session.FilePath]) {
+			upd.Changed = append(upd.Changed, *r.session)
+		}
+	}
+	scanned := make([]string, len(roots))
+	for i, root := range roots {
+		scanned[i] = root.dir
+	}
+	upd.Removed = c.prune(scanned)
+
+	var sessions []Session
+	for _, r := range results {
+		if r.session != nil {
+			sessions = append(sessions, *r.session)
+		}
+	}
+	SortByLastActive(sessions)
+
+	return sessions, upd, fileErrs, nil
+}
+
+// applyProjects sets the project name and path of every loaded session from
+// its project directory, and returns the directories whose resolution
+// differs from the previous scan.
+func (l *Loader) applyProjects(results []parseResult) map[string]bool {
+	cwds := make(map[string][]string)
+	for _, r := range results {
+		if r.session == nil {
+			continue
+		}
+		dirCwds := cwds[r.projectDir]
+		if r.session.ProjectPath != "" {
+			dirCwds = append(dirCwds, r.session.ProjectPath)
+		}
+		cwds[r.projectDir] = dirCwds
+	}
+	if l.projects == nil {
+		l.projects = make(map[string]projectInfo)
+	}
+	projects := resolveProjects(l.projects, cwds)
+
+	renamed := make(map[string]bool)
+	for dir, info := range projects {
+		if prev, ok := l.shown[dir]; ok && prev != info {
+			renamed[dir] = true
+		}
+	}
+	l.shown = projects
+
+	for _, r := range results {
+		if r.session == nil {
+			continue
+		}
+		info := projects[r.projectDir]
+		r.session.Project = info.Name
+		if info.Path != "" {
+			r.session.ProjectPath = info.Path
+		}
+	}
+	return renamed
+}
+
+// applyChains links the loaded sessions into continuation chains and returns
+// the file paths of sessions whose links differ from the previous scan.
+func (l *Loader) applyChains(results []parseResult) map[string]bool {
+	linkChains(results)
+	shown := make(map[string]string)
+	relinked := make(map[string]bool)
+	for _, r := range results {
+		if r.session == nil {
+			continue
+		}
+		path := r.session.FilePath
+		shown[path] = r.session.Continues + ":" + strings.Join(r.session.Chain, ",")
+		if prev, ok := l.chains[path]; ok && prev != shown[path] {
+			relinked[path] = true
+		}
+	}
+	l.chains = shown
+	return relinked
+}
+
+// SortByLastActive sorts sessions most recently active first, with pinned
+// sessions ahead of the rest.
+func SortByLastActive(ss []Session) {
+	sort.SliceStable(ss, func(i, j int) bool {
+		if ss[i].Pinned != ss[j].Pinned {
+			return ss[i].Pinned
+		}
+		return ss[i].LastActive.After(ss[j].LastActive)
+	})
+}
+
+// parseConcurrently parses every job on a worker pool sized to the CPU count,
+// writing each outcome into results at the job's index.
+func parseConcurrently(jobs []parseJob, results []parseResult) {
+	workers := runtime.NumCPU()
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	ch := make(chan parseJob)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range ch {
+				if err := j.state.parse(); err != nil {
+					results[j.idx].err = err
+					continue
+				}
+				results[j.idx].session = j.state.session()
+				results[j.idx].links = j.state.Links
+			}
+		}()
+	}
+	for _, j := range jobs {
+		ch <- j
+	}
+	close(ch)
+	wg.Wait()
+}
@@ -3,189 +3,305 @@ package sessions
 import (
 	"bufio"
 	"encoding/json"
+	"io"
 	"os"
-	"path/filepath"
-	"sort"
 	"strings"
 	"time"
 )
 
 // jsonlEntry represents a single line in a session JSONL file.
 type jsonlEntry struct {
-	Type      string          `json:"type"`
-	SessionID string          `json:"sessionId"`
-	CWD       string          `json:"cwd"`
-	GitBranch string          `json:"gitBranch"`
-	Timestamp string          `json:"timestamp"`
-	IsMeta    bool            `json:"isMeta"`
-	Summary   string          `json:"summary"`
-	Message   json.RawMessage `json:"message"`
+	Type              string          `json:"type"`
+	UUID              string          `json:"uuid"`
+	ParentUUID        string          `json:"parentUuid"`
+	LogicalParentUUID string          `json:"logicalParentUuid"`
+	SessionID         string          `json:"sessionId"`
+	CWD               string          `json:"cwd"`
+	GitBranch         string          `json:"gitBranch"`
+	Timestamp         string          `json:"timestamp"`
+	IsMeta            bool            `json:"isMeta"`
+	IsSidechain       bool            `json:"isSidechain"`
+	IsCompactSummary  bool            `json:"isCompactSummary"`
+	Summary           string          `json:"summary"`
+	Message           json.RawMessage `json:"message"`
 }
 
 type messageContent struct {
+	ID      string          `json:"id"`
 	Role    string          `json:"role"`
+	Model   string          `json:"model"`
 	Content json.RawMessage `json:"content"`
+	Usage   *usageBlock     `json:"usage"`
 }
 
 // contentBlock represents a structured content block (text, tool_use, etc.)
 type contentBlock struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type      string          `json:"type"`
+	Text      string          `json:"text"`
+	Thinking  string          `json:"thinking"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	ToolUseID string          `json:"tool_use_id"`
+	Content   json.RawMessage `json:"content"`
+	IsError   bool            `json:"is_error"`
 }
 
-// claudeDir returns the path to ~/.claude/projects/
-func claudeDir() (string, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(home, ".claude", "projects"), nil
+// parseState is the running aggregate of a session file parsed up to Offset.
+// It is kept in the cache so that a file which only grew can be parsed from
+// where the previous pass stopped instead of from the beginning.
+type parseState struct {
+	Offset           int64 // bytes consumed so far; always at a line boundary
+	Session          Session
+	SummaryLine      string // raw summary from a "summary" entry
+	FirstUserMessage string
+	FirstTimestamp   time.Time
+	LastTimestamp    time.Time
+	LastMessageIDs   map[int]string // per thread, id of the last assistant message whose usage was counted
+	Threads          threads
+	Links            links
 }
 
-// decodeProjectName converts an encoded directory name back to a readable name.
-// e.g., "-Users-lagap-code-producthunt" -> "producthunt"
-func decodeProjectName(dirName string) string {
-	// The directory name is the path with / replaced by -
-	// We want just the last meaningful segment as the project name
-	parts := strings.Split(dirName, "-")
-	// Filter out empty parts and common path segments
-	for i := len(parts) - 1; i >= 0; i-- {
-		if parts[i] != "" {
-			return parts[i]
-		}
+func newParseState(path, projectDir string) *parseState {
+	return &parseState{
+		Session: Session{
+			FilePath: path,
+			Project:  projectDir,
+		},
 	}
-	return dirName
 }
 
-// LoadAll discovers and parses all session files.
-func LoadAll() ([]Session, error) {
-	dir, err := claudeDir()
+// parse decodes every complete line appended to the file since st.Offset.
+// A trailing line without a newline is only consumed if it is valid JSON;
+// otherwise it is assumed to be mid-write and left for the next pass.
+func (st *parseState) parse() error {
+	f, err := os.Open(st.Session.FilePath)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer f.Close()
 
-	projectDirs, err := os.ReadDir(dir)
-	if err != nil {
-		return nil, err
+	if st.Offset > 0 && !st.resumable(f) {
+		*st = *newParseState(st.Session.FilePath, st.Session.Project)
+	}
+	if _, err := f.Seek(st.Offset, io.SeekStart); err != nil {
+		return err
 	}
 
-	var sessions []Session
-	for _, pd := range projectDirs {
-		if !pd.IsDir() {
-			continue
+	r := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' && !json.Valid(line) {
+				return nil
+			}
+			st.Offset += int64(len(line))
+			st.consume(line)
 		}
-		projectDir := filepath.Join(dir, pd.Name())
-		projectName := decodeProjectName(pd.Name())
-
-		files, err := filepath.Glob(filepath.Join(projectDir, "*.jsonl"))
-		if err != nil {
-			continue
+		if err == io.EOF {
+			return nil
 		}
-
-		for _, f := range files {
-			s, err := parseSessionFile(f, projectName)
-			if err != nil || s == nil {
-				continue
-			}
-			sessions = append(sessions, *s)
+		if err != nil {
+			return err
 		}
 	}
+}
 
-	sort.Slice(sessions, func(i, j int) bool {
-		return sessions[i].LastActive.After(sessions[j].LastActive)
-	})
-
-	return sessions, nil
+// resumable reports whether the file still looks like an appended-to version
+// of what was parsed: it must be at least Offset bytes long and the byte just
+// before Offset must end a line. A rewritten file fails this check and is
+// parsed from scratch.
+func (st *parseState) resumable(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Size() < st.Offset {
+		return false
+	}
+	b := make([]byte, 1)
+	if _, err := f.ReadAt(b, st.Offset-1); err != nil {
+		return false
+	}
+	return b[0] == '\n'
 }
 
-// parseSessionFile parses a single .jsonl file into a Session.
-func parseSessionFile(path string, projectName string) (*Session, error) {
-	f, err := os.Open(path)
-	if err != nil {
-		return nil, err
+// consume folds a single JSONL line into the aggregate state.
+func (st *parseState) consume(line []byte) {
+	var entry jsonlEntry
+	if err := json.Unmarshal(line, &entry); err != nil {
+		return
+	}
+	s := &st.Session
+	thread := st.Threads.assign(&entry)
+	if thread == 0 {
+		st.Links.note(&entry)
 	}
-	defer f.Close()
 
-	s := &Session{
-		FilePath: path,
-		Project:  projectName,
+	// Extract summary
+	if entry.Type == "summary" && entry.Summary != "" {
+		st.SummaryLine = entry.Summary
 	}
 
-	var firstUserMessage string
-	var lastTimestamp time.Time
-	var messageTexts []string
+	// Extract session metadata from user/assistant messages
+	if entry.Type != "user" && entry.Type != "assistant" {
+		return
+	}
+	if id := fileSessionID(s.FilePath); id != "" && entry.SessionID != "" && entry.SessionID != id {
+		// History copied from the session this one continues is counted
+		// there, not here.
+		st.Links.copiedFrom(entry.SessionID)
+		return
+	}
+	if entry.SessionID != "" && s.ID == "" {
+		s.ID = entry.SessionID
+	}
+	// The summary Claude writes of the earlier conversation when compacting
+	// is not a message of this one.
+	if entry.IsCompactSummary {
+		entry.IsMeta = true
+	}
+	if entry.CWD != "" {
+		s.ProjectPath = entry.CWD
+	}
+	if entry.GitBranch != "" {
+		s.GitBranch = entry.GitBranch
+	}
+	var ts time.Time
+	if entry.Timestamp != "" {
+		if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+			ts = t
+			if t.After(st.LastTimestamp) {
+				st.LastTimestamp = t
+			}
+			if st.FirstTimestamp.IsZero() || t.Before(st.FirstTimestamp) {
+				st.FirstTimestamp = t
+			}
+		}
+	}
 
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 1024*1024), 10*1024*1024) // 10MB max line
+	// Sub-agent messages are counted on their own thread only.
+	if thread > 0 {
+		st.consumeSidechain(thread, &entry, ts)
+		return
+	}
 
-	for scanner.Scan() {
-		var entry jsonlEntry
-		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
-			continue
-		}
+	if !entry.IsMeta {
+		s.MessageCount++
+	}
 
-		// Extract summary
-		if entry.Type == "summary" && entry.Summary != "" {
-			s.Summary = entry.Summary
-		}
+	if entry.Type == "assistant" {
+		st.addUsage(&s.Usage, 0, entry.Message)
+	}
 
-		// Extract session metadata from user/assistant messages
-		if entry.Type == "user" || entry.Type == "assistant" {
-			if entry.SessionID != "" && s.ID == "" {
-				s.ID = entry.SessionID
-			}
-			if entry.CWD != "" {
-				s.ProjectPath = entry.CWD
-			}
-			if entry.GitBranch != "" {
-				s.GitBranch = entry.GitBranch
-			}
-			if entry.Timestamp != "" {
-				if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
-					if t.After(lastTimestamp) {
-						lastTimestamp = t
-					}
-				}
+	// Capture user message text
+	if entry.Type == "user" && !entry.IsMeta {
+		text := extractTextContent(entry.Message)
+		if text != "" {
+			if st.FirstUserMessage == "" {
+				st.FirstUserMessage = text
 			}
+		}
+	}
+}
 
-			if !entry.IsMeta {
-				s.MessageCount++
-			}
+// consumeSidechain folds a user or assistant entry of sub-agent thread n into
+// that thread's Sidechain.
+func (st *parseState) consumeSidechain(n int, entry *jsonlEntry, ts time.Time) {
+	for len(st.Session.Sidechains) < n {
+		st.Session.Sidechains = append(st.Session.Sidechains, Sidechain{})
+	}
+	c := &st.Session.Sidechains[n-1]
+	if !ts.IsZero() {
+		if ts.After(c.LastActive) {
+			c.LastActive = ts
+		}
+		if c.Created.IsZero() || ts.Before(c.Created) {
+			c.Created = ts
+		}
+	}
+	if entry.IsMeta {
+		return
+	}
+	c.MessageCount++
+	switch entry.Type {
+	case "assistant":
+		st.addUsage(&c.Usage, n, entry.Message)
+	case "user":
+		if c.Prompt == "" {
+			c.Prompt = extractTextContent(entry.Message)
+		}
+	}
+}
 
-			// Capture user message text
-			if entry.Type == "user" && !entry.IsMeta {
-				text := extractTextContent(entry.Message)
-				if text != "" {
-					if firstUserMessage == "" {
-						firstUserMessage = text
-					}
-					messageTexts = append(messageTexts, text)
-				}
-			}
+// addUsage adds an assistant message's token usage to its model's total in
+// usage. Claude Code writes one entry per content block, each repeating the
+// usage of the whole message, so only the first entry of each message on a
+// thread is counted.
+func (st *parseState) addUsage(usage *map[string]TokenUsage, thread int, raw json.RawMessage) {
+	var msg messageContent
+	if err := json.Unmarshal(raw, &msg); err != nil || msg.Usage == nil {
+		return
+	}
+	if msg.ID != "" {
+		if msg.ID == st.LastMessageIDs[thread] {
+			return
+		}
+		if st.LastMessageIDs == nil {
+			st.LastMessageIDs = make(map[int]string)
 		}
+		st.LastMessageIDs[thread] = msg.ID
+	}
+	if msg.Model == "" || msg.Model == "<synthetic>" {
+		return
+	}
+	if *usage == nil {
+		*usage = make(map[string]TokenUsage)
 	}
+	(*usage)[msg.Model] = (*usage)[msg.Model].Add(msg.Usage.tokens())
+}
 
-	// Skip sessions with no messages
-	if s.ID == "" || s.MessageCount == 0 {
-		return nil, nil
+// session builds the Session from the aggregate state, or nil if the file
+// holds no messages yet.
+func (st *parseState) session() *Session {
+	if st.Session.ID == "" || st.Session.MessageCount == 0 {
+		return nil
 	}
 
-	s.LastActive = lastTimestamp
-	s.MessageText = strings.Join(messageTexts, "\n")
+	s := st.Session
+	s.Created = st.FirstTimestamp
+	s.LastActive = st.LastTimestamp
+
+	// Copy the usage maps so resuming the parse never mutates a returned Session.
+	s.Usage = copyUsage(st.Session.Usage)
+	if st.Session.Sidechains != nil {
+		s.Sidechains = make([]Sidechain, len(st.Session.Sidechains))
+		for i, c := range st.Session.Sidechains {
+			c.Usage = copyUsage(c.Usage)
+			s.Sidechains[i] = c
+		}
+	}
 
+	s.Summary = st.SummaryLine
 	if s.Summary == "" {
-		s.Summary = firstUserMessage
+		s.Summary = st.FirstUserMessage
 	}
 
 	// Clean up summary: collapse whitespace, remove newlines
 	s.Summary = strings.Join(strings.Fields(s.Summary), " ")
 
 	// Truncate long summaries
-	if len(s.Summary) > 200 {
-		s.Summary = s.Summary[:197] + "..."
-	}
+	s.Summary = Truncate(s.Summary, 200)
 
-	return s, nil
+	return &s
+}
+
+func copyUsage(usage map[string]TokenUsage) map[string]TokenUsage {
+	if usage == nil {
+		return nil
+	}
+	out := make(map[string]TokenUsage, len(usage))
+	for model, u := range usage {
+		out[model] = u
+	}
+	return out
 }
 
 // extractTextContent gets the text from a message content field.
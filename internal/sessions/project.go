@@ -0,0 +1,232 @@
+package sessions
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// projectInfo is what a project directory under ~/.claude/projects resolves to.
+type projectInfo struct {
+	Path string // the directory Claude was started in; "" if unknown
+	Name string // display name
+	Repo string // git directory of the enclosing repository, else Path
+}
+
+// EncodeProjectPath encodes a path the way Claude names its project
+// directories: every character other than an ASCII letter or digit becomes
+// '-', so "/home/me/my-app" and "/home/me/my.app" both become
+// "-home-me-my-app".
+func EncodeProjectPath(path string) string {
+	b := []byte(path)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			b[i] = '-'
+		}
+	}
+	return string(b)
+}
+
+// pathFromCwds recovers the path an encoded project directory name stands
+// for from the cwds its sessions recorded, or their parents, since a session
+// may have moved into a subdirectory. It returns "" if none matches.
+func pathFromCwds(dirName string, cwds []string) string {
+	target := EncodeProjectPath(dirName)
+	for _, cwd := range cwds {
+		for p := filepath.Clean(cwd); ; p = filepath.Dir(p) {
+			if EncodeProjectPath(p) == target {
+				return p
+			}
+			if parent := filepath.Dir(p); parent == p {
+				break
+			}
+		}
+	}
+	return ""
+}
+
+// pathFromFilesystem recovers the path an encoded project directory name
+// stands for by searching the filesystem for a directory that encodes to it.
+// It returns "" if there is none, e.g. because the directory was deleted.
+func pathFromFilesystem(dirName string) string {
+	target := EncodeProjectPath(dirName)
+	if !strings.HasPrefix(target, "-") {
+		return ""
+	}
+	return findEncodedPath(string(filepath.Separator), target)
+}
+
+// findEncodedPath searches below dir for a directory whose path encodes to
+// target, descending only into entries that keep the encoding a prefix of it.
+func findEncodedPath(dir, target string) string {
+	if EncodeProjectPath(dir) == target {
+		return dir
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		child := filepath.Join(dir, e.Name())
+		enc := EncodeProjectPath(child)
+		if enc != target && !strings.HasPrefix(target, enc+"-") {
+			continue
+		}
+		if info, err := os.Stat(child); err != nil || !info.IsDir() {
+			continue
+		}
+		if found := findEncodedPath(child, target); found != "" {
+			return found
+		}
+	}
+	return ""
+}
+
+// newProjectInfo describes a project path. Its display name is the name of
+// its git repository's origin remote, else the repository's directory name,
+// else the path's last element.
+func newProjectInfo(path string) projectInfo {
+	info := projectInfo{Path: path, Name: filepath.Base(path), Repo: path}
+	root, gitDir := findRepo(path)
+	if root == "" {
+		return info
+	}
+	info.Name = filepath.Base(root)
+	if gitDir != "" {
+		info.Repo = gitDir
+		if name := remoteRepoName(gitDir); name != "" {
+			info.Name = name
+		}
+	}
+	return info
+}
+
+// findRepo walks up from path to the enclosing git work tree and returns its
+// root and the directory holding its config. For a linked worktree that is
+// the main repository's git directory, so worktrees share their repo's name.
+func findRepo(path string) (root, gitDir string) {
+	for p := filepath.Clean(path); ; p = filepath.Dir(p) {
+		dotGit := filepath.Join(p, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return p, dotGit
+			}
+			return p, linkedGitDir(p, dotGit)
+		}
+		if parent := filepath.Dir(p); parent == p {
+			return "", ""
+		}
+	}
+}
+
+// linkedGitDir follows a .git file ("gitdir: ...") to the common git
+// directory of the repository it belongs to.
+func linkedGitDir(root, dotGit string) string {
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(root, dir)
+	}
+	if common, err := os.ReadFile(filepath.Join(dir, "commondir")); err == nil {
+		c := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(c) {
+			c = filepath.Join(dir, c)
+		}
+		return filepath.Clean(c)
+	}
+	return dir
+}
+
+// remoteRepoName reads the repository name from the URL of the origin
+// remote, or of the first remote if there is no origin, in gitDir's config.
+func remoteRepoName(gitDir string) string {
+	if gitDir == "" {
+		return ""
+	}
+	f, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	var section, first string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "[") {
+			section = line
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(key) != "url" || !strings.HasPrefix(section, "[remote ") {
+			continue
+		}
+		name := repoNameFromURL(strings.TrimSpace(value))
+		if section == `[remote "origin"]` && name != "" {
+			return name
+		}
+		if first == "" {
+			first = name
+		}
+	}
+	return first
+}
+
+// repoNameFromURL extracts "repo" from remote URLs such as
+// git@github.com:me/repo.git or https://github.com/me/repo.
+func repoNameFromURL(url string) string {
+	url = strings.TrimSuffix(strings.TrimRight(url, "/"), ".git")
+	if i := strings.LastIndexAny(url, "/:"); i >= 0 {
+		url = url[i+1:]
+	}
+	return url
+}
+
+// resolveProjects returns the project info for each encoded directory name
+// in cwds, given the cwds its sessions recorded, falling back to the encoded
+// name when the path cannot be recovered. projects caches the results across
+// scans; the filesystem is only searched the first time a name is seen.
+// Display names shared by different repositories are qualified with the
+// parent directory, as in "work/api" and "personal/api".
+func resolveProjects(projects map[string]projectInfo, cwds map[string][]string) map[string]projectInfo {
+	out := make(map[string]projectInfo, len(cwds))
+	for dirName, dirCwds := range cwds {
+		info, ok := projects[dirName]
+		if !ok || info.Path == "" {
+			path := pathFromCwds(dirName, dirCwds)
+			if path == "" && !ok {
+				path = pathFromFilesystem(dirName)
+			}
+			if path != "" {
+				info = newProjectInfo(path)
+			} else {
+				info = projectInfo{Name: dirName, Repo: dirName}
+			}
+			projects[dirName] = info
+		}
+		out[dirName] = info
+	}
+
+	repos := make(map[string]map[string]bool)
+	for _, info := range out {
+		if repos[info.Name] == nil {
+			repos[info.Name] = make(map[string]bool)
+		}
+		repos[info.Name][info.Repo] = true
+	}
+	for dirName, info := range out {
+		if len(repos[info.Name]) > 1 && info.Path != "" {
+			info.Name = filepath.Base(filepath.Dir(info.Path)) + "/" + info.Name
+			out[dirName] = info
+		}
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package sessions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Root is a Claude data directory, such as ~/.claude or a directory
+// CLAUDE_CONFIG_DIR points to, whose projects/ subdirectory holds sessions.
+type Root struct {
+	Name string // label shown with the root's sessions
+	Dir  string
+}
+
+// ProjectsDir returns the directory holding the root's sessions.
+func (r Root) ProjectsDir() string {
+	return filepath.Join(r.Dir, "projects")
+}
+
+// ArchiveDir returns the directory the root's sessions are archived to. It
+// mirrors the projects directory layout and sits next to it so archiving is a
+// rename on the same filesystem.
+func (r Root) ArchiveDir() string {
+	return filepath.Join(r.Dir, "claude-manager-archive")
+}
+
+// defaultDir returns ~/.claude, where Claude keeps its data unless
+// CLAUDE_CONFIG_DIR says otherwise.
+func defaultDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".claude"), nil
+}
+
+// DefaultRoots returns the root CLAUDE_CONFIG_DIR names, or ~/.claude if it
+// is unset. Like Claude, it reads the variable as a single directory; more
+// roots come from --root or the config file.
+func DefaultRoots() ([]Root, error) {
+	dir := os.Getenv("CLAUDE_CONFIG_DIR")
+	if dir == "" {
+		var err error
+		if dir, err = defaultDir(); err != nil {
+			return nil, err
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	return []Root{{Name: rootName(dir), Dir: dir}}, nil
+}
+
+// ParseRoot parses a root given as "dir" or "name=dir". A leading ~ in dir
+// is expanded. Without a name, the root is named after the directory, e.g.
+// "claude-work" for ~/.claude-work.
+func ParseRoot(spec string) (Root, error) {
+	name, dir, ok := strings.Cut(spec, "=")
+	if !ok {
+		name, dir = "", spec
+	}
+	if dir == "" {
+		return Root{}, fmt.Errorf("invalid root %q: missing directory", spec)
+	}
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Root{}, err
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return Root{}, err
+	}
+	if name == "" {
+		name = rootName(dir)
+	}
+	return Root{Name: name, Dir: dir}, nil
+}
+
+func rootName(dir string) string {
+	return strings.TrimPrefix(filepath.Base(dir), ".")
+}
+
+// sessionRoot returns the root a session was loaded from.
+func sessionRoot(s Session) (Root, error) {
+	if s.ConfigDir != "" {
+		return Root{Name: s.Root, Dir: s.ConfigDir}, nil
+	}
+	dir, err := defaultDir()
+	if err != nil {
+		return Root{}, err
+	}
+	return Root{Name: rootName(dir), Dir: dir}, nil
+}
+
+// ClaudeEnv returns env adjusted for running claude on sessions of the given
+// root directory: CLAUDE_CONFIG_DIR points at it, or is removed for
+// ~/.claude, which Claude uses by default. An empty dir leaves env as is.
+func ClaudeEnv(env []string, dir string) []string {
+	if dir == "" {
+		return env
+	}
+	out := make([]string, 0, len(env)+1)
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, "CLAUDE_CONFIG_DIR=") {
+			out = append(out, kv)
+		}
+	}
+	if def, err := defaultDir(); err != nil || filepath.Clean(dir) != def {
+		out = append(out, "CLAUDE_CONFIG_DIR="+dir)
+	}
+	return out
+}
@@ -8,14 +8,28 @@ import (
 // Session represents a parsed Claude Code session.
 type Session struct {
 	ID           string
-	Project      string    // Human-readable project name (decoded from directory)
-	ProjectPath  string    // Actual filesystem path (cwd from session data)
-	Summary      string    // From summary line, or first user message as fallback
-	GitBranch    string    // Git branch at time of session
-	LastActive   time.Time // Timestamp of last message
-	MessageCount int       // Total user + assistant messages
-	FilePath     string    // Path to the .jsonl file
-	MessageText  string    // Concatenated user message text for full-text search
+	Project      string                // Display name: git remote or repo name, else directory name
+	ProjectPath  string                // Directory the session was started in, else its last cwd
+	Summary      string                // From summary line, or first user message as fallback
+	GitBranch    string                // Git branch at time of session
+	Created      time.Time             // Timestamp of first message
+	LastActive   time.Time             // Timestamp of last message
+	MessageCount int                   // User + assistant messages, not counting sub-agents
+	FilePath     string                // Path to the .jsonl file
+	Usage        map[string]TokenUsage // Token usage per model, from assistant messages
+	Sidechains   []Sidechain           // Sub-agent threads, in order of their first message
+	Continues    string                // ID of the session this one continues after a resume or compaction
+	Chain        []string              // IDs of all sessions linked with this one by continuation, oldest first; nil if none
+	Archived     bool                  // File lives in the claude-manager archive
+	Root         string                // Name of the data root the session was loaded from
+	ConfigDir    string                // That root's directory, for CLAUDE_CONFIG_DIR
+
+	// User data from claude-manager's sidecar file; see internal/meta.
+	Tags            []string
+	Pinned          bool
+	Note            string
+	Title           string // custom title; when set it replaces Summary
+	OriginalSummary string // the derived summary while Title replaces it
 }
 
 // TimeAgo returns a human-readable relative time string.
@@ -38,3 +52,19 @@ func (s Session) TimeAgo() string {
 		return fmt.Sprintf("%dmo ago", months)
 	}
 }
+
+// Truncate shortens s to at most maxLen runes, ending it with "..." if
+// there is room. It never cuts a character in half.
+func Truncate(s string, maxLen int) string {
+	if maxLen < 0 {
+		maxLen = 0
+	}
+	runes := []rune(s)
+	if len(runes) <= maxLen {
+		return s
+	}
+	if maxLen <= 3 {
+		return string(runes[:maxLen])
+	}
+	return string(runes[:maxLen-3]) + "..."
+}
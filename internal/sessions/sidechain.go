@@ -0,0 +1,45 @@
+package sessions
+
+import "time"
+
+// Sidechain is a sub-agent thread: the messages of a Task tool run, which
+// Claude Code records in the parent session's file marked isSidechain.
+type Sidechain struct {
+	Prompt       string // the task the sub-agent was given
+	Created      time.Time
+	LastActive   time.Time
+	MessageCount int
+	Usage        map[string]TokenUsage
+}
+
+// TotalUsage sums token usage across all models the sub-agent used.
+func (c Sidechain) TotalUsage() TokenUsage {
+	return SumUsage(c.Usage)
+}
+
+// threads assigns the entries of a session file to threads: 0 for the main
+// conversation and 1, 2, ... for sidechains in order of their first entry. A
+// sidechain entry joins the thread of its parent entry, or starts a new one
+// when its parent is not a sidechain entry seen so far.
+type threads struct {
+	Of    map[string]int // thread of each sidechain entry, by uuid
+	Count int
+}
+
+func (t *threads) assign(e *jsonlEntry) int {
+	if !e.IsSidechain {
+		return 0
+	}
+	n, ok := t.Of[e.ParentUUID]
+	if !ok || e.ParentUUID == "" {
+		t.Count++
+		n = t.Count
+	}
+	if e.UUID != "" {
+		if t.Of == nil {
+			t.Of = make(map[string]int)
+		}
+		t.Of[e.UUID] = n
+	}
+	return n
+}
@@ -0,0 +1,57 @@
+package sessions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTime parses a point in time given either relative to now or as a
+// date. Relative values count back from now: "30m", "12h", "3d", "2w",
+// "6mo", "1y". Absolute values are "2006-01-02" (local midnight) or RFC3339.
+// "today" and "yesterday" mean local midnight of that day.
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	v := strings.ToLower(strings.TrimSpace(value))
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch v {
+	case "":
+		return time.Time{}, fmt.Errorf("empty time")
+	case "today":
+		return midnight, nil
+	case "yesterday":
+		return midnight.AddDate(0, 0, -1), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", v, now.Location()); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	// Relative: a number followed by a unit.
+	i := 0
+	for i < len(v) && v[i] >= '0' && v[i] <= '9' {
+		i++
+	}
+	n, err := strconv.Atoi(v[:i])
+	if i == 0 || err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 3d, 2w, 2025-01-01)", value)
+	}
+	switch v[i:] {
+	case "m", "min":
+		return now.Add(-time.Duration(n) * time.Minute), nil
+	case "h":
+		return now.Add(-time.Duration(n) * time.Hour), nil
+	case "d":
+		return now.AddDate(0, 0, -n), nil
+	case "w":
+		return now.AddDate(0, 0, -7*n), nil
+	case "mo":
+		return now.AddDate(0, -n, 0), nil
+	case "y":
+		return now.AddDate(-n, 0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use e.g. 3d, 2w, 2025-01-01)", value)
+}
@@ -0,0 +1,311 @@
+package sessions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// BlockKind identifies the type of a transcript content block.
+type BlockKind string
+
+const (
+	BlockText       BlockKind = "text"
+	BlockThinking   BlockKind = "thinking"
+	BlockToolUse    BlockKind = "tool_use"
+	BlockToolResult BlockKind = "tool_result"
+)
+
+// Block is a single piece of content within a turn.
+type Block struct {
+	Kind      BlockKind
+	Text      string // text, thinking, or tool_result output
+	ToolName  string // tool_use only
+	ToolInput string // tool_use only, pretty-printed JSON
+	ToolID    string // tool_use id, or the id a tool_result answers
+	IsError   bool   // tool_result only
+}
+
+// Turn is one user or assistant message in a transcript. Consecutive
+// assistant entries that belong to the same API message are merged.
+type Turn struct {
+	Role      string // "user" or "assistant"
+	Timestamp time.Time
+	Blocks    []Block
+	Offset    int64  // byte offset of the turn's first line in the session file
+	UUID      string // uuid of the turn's first entry
+	Sidechain int    // sub-agent thread, numbered as in Session.Sidechains from 1; 0 for the main conversation
+}
+
+// IsUserPrompt reports whether the turn contains text typed by the user, as
+// opposed to a user turn that only carries tool results or a sub-agent's task.
+func (t Turn) IsUserPrompt() bool {
+	if t.Role != "user" || t.Sidechain > 0 {
+		return false
+	}
+	for _, b := range t.Blocks {
+		if b.Kind == BlockText {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadTranscript reads every user and assistant turn from a session file,
+// including those of sub-agents, in file order.
+func LoadTranscript(path string) ([]Turn, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var turns []Turn
+	var th threads
+	lastMessageID := make(map[int]string) // per thread
+	lastTurn := make(map[int]int)         // per thread, index into turns
+	var offset int64
+
+	r := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		lineOffset := offset
+		offset += int64(len(line))
+		if len(bytes.TrimSpace(line)) > 0 {
+			var entry jsonlEntry
+			if json.Unmarshal(line, &entry) == nil {
+				thread := th.assign(&entry)
+				var msg messageContent
+				if (entry.Type == "user" || entry.Type == "assistant") && !entry.IsMeta && json.Unmarshal(entry.Message, &msg) == nil {
+					blocks := parseBlocks(msg.Content)
+					ts, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+
+					// Claude Code writes one entry per content block of an
+					// assistant message; fold them back into one turn.
+					// Entries without blocks leave no turn and so do not
+					// interrupt a message.
+					last, ok := lastTurn[thread]
+					if entry.Type == "assistant" && msg.ID != "" && msg.ID == lastMessageID[thread] && ok && turns[last].Role == "assistant" {
+						turns[last].Blocks = append(turns[last].Blocks, blocks...)
+					} else if len(blocks) > 0 {
+						turns = append(turns, Turn{Role: entry.Type, Timestamp: ts, Blocks: blocks, Offset: lineOffset, UUID: entry.UUID, Sidechain: thread})
+						lastTurn[thread] = len(turns) - 1
+						lastMessageID[thread] = msg.ID
+					}
+				}
+			}
+		}
+		if err == io.EOF {
+			return turns, nil
+		}
+		if err != nil {
+			return turns, err
+		}
+	}
+}
+
+// parseBlocks converts a message content field, either a plain string or an
+// array of content blocks, into transcript blocks.
+func parseBlocks(raw json.RawMessage) []Block {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		if str = strings.TrimSpace(str); str == "" {
+			return nil
+		}
+		return []Block{{Kind: BlockText, Text: str}}
+	}
+
+	var cbs []contentBlock
+	if err := json.Unmarshal(raw, &cbs); err != nil {
+		return nil
+	}
+
+	var blocks []Block
+	for _, cb := range cbs {
+		switch cb.Type {
+		case "text":
+			if text := strings.TrimSpace(cb.Text); text != "" {
+				blocks = append(blocks, Block{Kind: BlockText, Text: text})
+			}
+		case "thinking":
+			if text := strings.TrimSpace(cb.Thinking); text != "" {
+				blocks = append(blocks, Block{Kind: BlockThinking, Text: text})
+			}
+		case "tool_use":
+			blocks = append(blocks, Block{
+				Kind:      BlockToolUse,
+				ToolName:  cb.Name,
+				ToolInput: prettyJSON(cb.Input),
+				ToolID:    cb.ID,
+			})
+		case "tool_result":
+			blocks = append(blocks, Block{
+				Kind:    BlockToolResult,
+				Text:    toolResultText(cb.Content),
+				ToolID:  cb.ToolUseID,
+				IsError: cb.IsError,
+			})
+		}
+	}
+	return blocks
+}
+
+// toolResultText flattens tool_result content, which is either a string or
+// an array of text and image blocks.
+func toolResultText(raw json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return strings.TrimRight(str, "\n")
+	}
+
+	var cbs []contentBlock
+	if err := json.Unmarshal(raw, &cbs); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, cb := range cbs {
+		switch cb.Type {
+		case "text":
+			parts = append(parts, strings.TrimRight(cb.Text, "\n"))
+		case "image":
+			parts = append(parts, "[image]")
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func prettyJSON(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return buf.String()
+}
+
+// Message is the searchable content of one user or assistant line of a
+// session file.
+type Message struct {
+	Role      string // "user" or "assistant"
+	Timestamp time.Time
+	Text      string // as returned by EntryText
+	Offset    int64  // byte offset of the line in the session file
+}
+
+// EntryText returns the searchable text of one session file line: the text,
+// thinking, tool calls and tool output of a user or assistant message. ok is
+// false for any other line, including meta messages.
+func EntryText(line []byte) (role, text string, ok bool) {
+	m, ok := parseMessage(line)
+	return m.Role, m.Text, ok
+}
+
+func parseMessage(line []byte) (Message, bool) {
+	var entry jsonlEntry
+	if json.Unmarshal(line, &entry) != nil || (entry.Type != "user" && entry.Type != "assistant") || entry.IsMeta {
+		return Message{}, false
+	}
+	var msg messageContent
+	if json.Unmarshal(entry.Message, &msg) != nil {
+		return Message{}, false
+	}
+	var parts []string
+	for _, b := range parseBlocks(msg.Content) {
+		switch b.Kind {
+		case BlockToolUse:
+			parts = append(parts, b.ToolName, b.ToolInput)
+		default:
+			parts = append(parts, b.Text)
+		}
+	}
+	ts, _ := time.Parse(time.RFC3339Nano, entry.Timestamp)
+	return Message{Role: entry.Type, Timestamp: ts, Text: strings.Join(parts, "\n")}, true
+}
+
+// UserText returns the user prompts of a session file, one after another.
+// Sub-agent prompts and history copied from an earlier session are left out.
+func UserText(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	id := fileSessionID(path)
+	var texts []string
+	r := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		var e jsonlEntry
+		if json.Unmarshal(bytes.TrimSpace(line), &e) == nil && e.Type == "user" &&
+			!e.IsMeta && !e.IsSidechain && !e.IsCompactSummary &&
+			(id == "" || e.SessionID == "" || e.SessionID == id) {
+			if text := extractTextContent(e.Message); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// ReadMessage reads the message on the line of path starting at offset.
+func ReadMessage(path string, offset int64) (Message, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Message{}, err
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(io.NewSectionReader(f, offset, 1<<62)).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return Message{}, err
+	}
+	m, ok := parseMessage(bytes.TrimSpace(line))
+	if !ok {
+		return Message{}, fmt.Errorf("no message at offset %d of %s", offset, path)
+	}
+	m.Offset = offset
+	return m, nil
+}
+
+// ScanMessages calls fn with each message of path in order, until fn
+// returns false.
+func ScanMessages(path string, fn func(Message) bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var offset int64
+	r := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		if m, ok := parseMessage(bytes.TrimSpace(line)); ok {
+			m.Offset = offset
+			if !fn(m) {
+				return nil
+			}
+		}
+		offset += int64(len(line))
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package sessions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// Node is one entry of a session's main conversation, linked to the entry
+// it follows by parentUuid.
+type Node struct {
+	UUID      string
+	Type      string // entry type: "user", "assistant", "system", ...
+	Timestamp time.Time
+	Offset    int64  // byte offset of the entry's line in the session file
+	Text      string // first text of a user prompt or assistant message
+	Parent    *Node
+	Children  []*Node
+}
+
+// IsMessage reports whether the node is a user prompt or an assistant
+// message, as opposed to a tool result, system or bookkeeping entry.
+func (n *Node) IsMessage() bool {
+	return n.Type == "assistant" || n.Type == "user" && n.Text != ""
+}
+
+// Conversation is the tree of a session's main conversation. Editing an
+// earlier message, rewinding or retrying appends a new entry whose parent is
+// an older one, so the file holds every attempt; the tree tells them apart.
+type Conversation struct {
+	Nodes []*Node // in file order
+	Roots []*Node
+	Leaf  *Node // the active leaf: the last message written; nil if none
+}
+
+// Branch is a part of the conversation that was abandoned in favour of the
+// active path.
+type Branch struct {
+	Fork  *Node   // last entry the branch shares with the active path; nil if none
+	Nodes []*Node // the branch's own entries after Fork, oldest first, ending at its leaf
+}
+
+// Leaf returns the branch's last entry.
+func (b Branch) Leaf() *Node {
+	return b.Nodes[len(b.Nodes)-1]
+}
+
+// Messages counts the user prompts and assistant messages of the branch.
+func (b Branch) Messages() int {
+	n := 0
+	for _, node := range b.Nodes {
+		if node.IsMessage() {
+			n++
+		}
+	}
+	return n
+}
+
+// FirstMessage returns the branch's first user prompt or assistant message,
+// or nil.
+func (b Branch) FirstMessage() *Node {
+	for _, node := range b.Nodes {
+		if node.IsMessage() {
+			return node
+		}
+	}
+	return nil
+}
+
+// treeEntry is the part of a session file line needed to build the tree.
+type treeEntry struct {
+	Type              string          `json:"type"`
+	UUID              string          `json:"uuid"`
+	ParentUUID        string          `json:"parentUuid"`
+	LogicalParentUUID string          `json:"logicalParentUuid"`
+	Timestamp         string          `json:"timestamp"`
+	IsMeta            bool            `json:"isMeta"`
+	IsSidechain       bool            `json:"isSidechain"`
+	Message           json.RawMessage `json:"message"`
+}
+
+// LoadConversation reads the conversation tree of a session file. Sub-agent
+// entries are left out. Where compaction started a new chain, the chain is
+// attached to the entry it logically follows.
+func LoadConversation(path string) (*Conversation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := &Conversation{}
+	parents := make(map[*Node]string)
+	var offset int64
+	r := bufio.NewReaderSize(f, 1024*1024)
+	for {
+		line, err := r.ReadBytes('\n')
+		lineOffset := offset
+		offset += int64(len(line))
+		var e treeEntry
+		if len(bytes.TrimSpace(line)) > 0 && json.Unmarshal(line, &e) == nil && e.UUID != "" && !e.IsSidechain {
+			n := &Node{UUID: e.UUID, Type: e.Type, Offset: lineOffset}
+			n.Timestamp, _ = time.Parse(time.RFC3339Nano, e.Timestamp)
+			if !e.IsMeta {
+				n.Text = nodeText(e.Type, e.Message)
+			}
+			parent := e.ParentUUID
+			if parent == "" {
+				parent = e.LogicalParentUUID
+			}
+			parents[n] = parent
+			c.Nodes = append(c.Nodes, n)
+			if n.IsMessage() {
+				c.Leaf = n
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	byUUID := make(map[string]*Node, len(c.Nodes))
+	for _, n := range c.Nodes {
+		byUUID[n.UUID] = n
+	}
+	for _, n := range c.Nodes {
+		if p := byUUID[parents[n]]; p != nil && p != n {
+			n.Parent = p
+			p.Children = append(p.Children, n)
+		} else {
+			c.Roots = append(c.Roots, n)
+		}
+	}
+	return c, nil
+}
+
+// nodeText returns the first text of a user prompt or assistant message.
+func nodeText(typ string, raw json.RawMessage) string {
+	switch typ {
+	case "user":
+		return extractTextContent(raw)
+	case "assistant":
+		var msg messageContent
+		if json.Unmarshal(raw, &msg) != nil {
+			return ""
+		}
+		for _, b := range parseBlocks(msg.Content) {
+			if b.Kind == BlockText {
+				return b.Text
+			}
+		}
+	}
+	return ""
+}
+
+// Path returns the entries from the root of leaf's tree down to leaf.
+func (c *Conversation) Path(leaf *Node) []*Node {
+	var path []*Node
+	seen := make(map[*Node]bool)
+	for n := leaf; n != nil && !seen[n]; n = n.Parent {
+		seen[n] = true
+		path = append(path, n)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// ActivePath returns the path to the active leaf, the conversation as Claude
+// resumes it.
+func (c *Conversation) ActivePath() []*Node {
+	return c.Path(c.Leaf)
+}
+
+// Branches returns the abandoned branches, oldest first: for each leaf other
+// than the active one, the entries leading to it that are not on the active
+// path. Branches without a user prompt or assistant message, such as failed
+// API calls that were retried, are left out.
+func (c *Conversation) Branches() []Branch {
+	active := make(map[*Node]bool)
+	for _, n := range c.ActivePath() {
+		active[n] = true
+	}
+	var branches []Branch
+	for _, n := range c.Nodes {
+		if len(n.Children) > 0 || active[n] {
+			continue
+		}
+		var b Branch
+		for p := n; p != nil && !active[p]; p = p.Parent {
+			b.Nodes = append(b.Nodes, p)
+			b.Fork = p.Parent
+			if len(b.Nodes) > len(c.Nodes) {
+				break // parent cycle
+			}
+		}
+		for i, j := 0, len(b.Nodes)-1; i < j; i, j = i+1, j-1 {
+			b.Nodes[i], b.Nodes[j] = b.Nodes[j], b.Nodes[i]
+		}
+		if b.Messages() > 0 {
+			branches = append(branches, b)
+		}
+	}
+	sort.SliceStable(branches, func(i, j int) bool {
+		return branches[i].Leaf().Offset < branches[j].Leaf().Offset
+	})
+	return branches
+}
+
+// TurnsOn returns the turns of a transcript that belong to path: main
+// conversation turns whose first entry is on it, and sub-agent threads whose
+// first entry follows one on it. Turns read without entry ids are kept.
+func (c *Conversation) TurnsOn(turns []Turn, path []*Node) []Turn {
+	on := make(map[string]bool, len(path))
+	for _, n := range path {
+		on[n.UUID] = true
+	}
+	// Sub-agent threads are not linked to the main conversation; attach
+	// each to the main entry written just before it.
+	agents := make(map[int]bool)
+	for _, t := range turns {
+		if t.Sidechain == 0 {
+			continue
+		}
+		if _, seen := agents[t.Sidechain]; seen {
+			continue
+		}
+		i := sort.Search(len(c.Nodes), func(i int) bool { return c.Nodes[i].Offset > t.Offset })
+		agents[t.Sidechain] = i == 0 || on[c.Nodes[i-1].UUID]
+	}
+
+	out := make([]Turn, 0, len(turns))
+	for _, t := range turns {
+		if t.Sidechain > 0 && agents[t.Sidechain] || t.Sidechain == 0 && (t.UUID == "" || on[t.UUID]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
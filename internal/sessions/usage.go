@@ -0,0 +1,91 @@
+package sessions
+
+import "fmt"
+
+// TokenUsage holds token counts reported in assistant messages' usage blocks.
+type TokenUsage struct {
+	Input         int64
+	Output        int64
+	CacheCreation int64
+	CacheRead     int64
+	Messages      int // assistant messages the counts were summed from
+}
+
+// Add returns the sum of two usages.
+func (u TokenUsage) Add(o TokenUsage) TokenUsage {
+	return TokenUsage{
+		Input:         u.Input + o.Input,
+		Output:        u.Output + o.Output,
+		CacheCreation: u.CacheCreation + o.CacheCreation,
+		CacheRead:     u.CacheRead + o.CacheRead,
+		Messages:      u.Messages + o.Messages,
+	}
+}
+
+// Total returns all tokens, including cache writes and reads.
+func (u TokenUsage) Total() int64 {
+	return u.Input + u.Output + u.CacheCreation + u.CacheRead
+}
+
+// usageBlock mirrors the "usage" object of an assistant message.
+type usageBlock struct {
+	InputTokens              int64 `json:"input_tokens"`
+	OutputTokens             int64 `json:"output_tokens"`
+	CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+}
+
+func (u usageBlock) tokens() TokenUsage {
+	return TokenUsage{
+		Input:         u.InputTokens,
+		Output:        u.OutputTokens,
+		CacheCreation: u.CacheCreationInputTokens,
+		CacheRead:     u.CacheReadInputTokens,
+		Messages:      1,
+	}
+}
+
+// TotalUsage sums token usage across all models used in the session, not
+// counting sub-agents.
+func (s Session) TotalUsage() TokenUsage {
+	return SumUsage(s.Usage)
+}
+
+// SumUsage sums per-model token usage.
+func SumUsage(usage map[string]TokenUsage) TokenUsage {
+	var total TokenUsage
+	for _, u := range usage {
+		total = total.Add(u)
+	}
+	return total
+}
+
+// FormatTokens renders a token count compactly, e.g. 950, 12.3k, 4.1M.
+func FormatTokens(n int64) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1000000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+}
+
+// AllUsage returns token usage per model including the session's sub-agents,
+// i.e. everything the session was billed for.
+func (s Session) AllUsage() map[string]TokenUsage {
+	if len(s.Sidechains) == 0 {
+		return s.Usage
+	}
+	all := make(map[string]TokenUsage, len(s.Usage))
+	for model, u := range s.Usage {
+		all[model] = u
+	}
+	for _, c := range s.Sidechains {
+		for model, u := range c.Usage {
+			all[model] = all[model].Add(u)
+		}
+	}
+	return all
+}
@@ -0,0 +1,279 @@
+// Package stats aggregates session activity, token usage and cost into
+// grouped reports.
+package stats
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"claude-manager/internal/pricing"
+	"claude-manager/internal/sessions"
+)
+
+// GroupBy selects how sessions are bucketed.
+type GroupBy string
+
+const (
+	ByDay     GroupBy = "day"
+	ByWeek    GroupBy = "week"
+	ByProject GroupBy = "project"
+	ByBranch  GroupBy = "branch"
+	ByModel   GroupBy = "model"
+)
+
+// ParseGroupBy validates a --by value.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch g := GroupBy(s); g {
+	case ByDay, ByWeek, ByProject, ByBranch, ByModel:
+		return g, nil
+	}
+	return "", fmt.Errorf("unknown grouping %q (want day, week, project, branch or model)", s)
+}
+
+// Row is one bucket of a report.
+type Row struct {
+	Key      string
+	Sessions int
+	Messages int
+	Tokens   sessions.TokenUsage
+	Cost     float64
+}
+
+func (r *Row) add(o Row) {
+	r.Sessions += o.Sessions
+	r.Messages += o.Messages
+	r.Tokens = r.Tokens.Add(o.Tokens)
+	r.Cost += o.Cost
+}
+
+// Report is the result of aggregating sessions.
+type Report struct {
+	GroupBy GroupBy
+	Rows    []Row
+	Total   Row
+}
+
+// Aggregate buckets sessions by the given grouping. Time groupings use the
+// local day or ISO week in which each session was last active and are sorted
+// chronologically; a session active over several days counts entirely
+// towards its last. Other groupings are sorted by cost, highest first.
+//
+// When grouping by model, each session contributes its usage to every model
+// it used, and Messages counts that model's assistant messages rather than
+// the session's total.
+//
+// A continuation chain counts as one session, grouped by its latest part.
+func Aggregate(ss []sessions.Session, by GroupBy, prices pricing.Table) Report {
+	ss = mergeChains(ss)
+	buckets := make(map[string]*Row)
+	bucket := func(key string) *Row {
+		r, ok := buckets[key]
+		if !ok {
+			r = &Row{Key: key}
+			buckets[key] = r
+		}
+		return r
+	}
+
+	rep := Report{GroupBy: by, Total: Row{Key: "TOTAL"}}
+	for _, s := range ss {
+		// Tokens and cost include sub-agents; messages are the session's own.
+		usage := s.AllUsage()
+		if by == ByModel {
+			for model, u := range usage {
+				bucket(model).add(Row{
+					Sessions: 1,
+					Messages: u.Messages,
+					Tokens:   u,
+					Cost:     prices.Cost(map[string]sessions.TokenUsage{model: u}),
+				})
+			}
+		} else {
+			bucket(groupKey(s, by)).add(Row{
+				Sessions: 1,
+				Messages: s.MessageCount,
+				Tokens:   sessions.SumUsage(usage),
+				Cost:     prices.Cost(usage),
+			})
+		}
+		rep.Total.add(Row{
+			Sessions: 1,
+			Messages: s.MessageCount,
+			Tokens:   sessions.SumUsage(usage),
+			Cost:     prices.Cost(usage),
+		})
+	}
+
+	for _, r := range buckets {
+		rep.Rows = append(rep.Rows, *r)
+	}
+	sort.Slice(rep.Rows, func(i, j int) bool {
+		a, b := rep.Rows[i], rep.Rows[j]
+		if by == ByDay || by == ByWeek {
+			return a.Key < b.Key
+		}
+		if a.Cost != b.Cost {
+			return a.Cost > b.Cost
+		}
+		return a.Key < b.Key
+	})
+	return rep
+}
+
+// mergeChains folds the parts of each continuation chain in ss into its
+// latest part, summing messages and usage. Sub-agent usage is folded into
+// Usage.
+func mergeChains(ss []sessions.Session) []sessions.Session {
+	out := make([]sessions.Session, 0, len(ss))
+	at := make(map[string]int)
+	for _, s := range ss {
+		if len(s.Chain) == 0 {
+			out = append(out, s)
+			continue
+		}
+		// A copy, as AllUsage may return the session's own map.
+		usage := make(map[string]sessions.TokenUsage)
+		for model, u := range s.AllUsage() {
+			usage[model] = u
+		}
+		i, ok := at[s.ChainID()]
+		if !ok {
+			at[s.ChainID()] = len(out)
+			s.Usage, s.Sidechains = usage, nil
+			out = append(out, s)
+			continue
+		}
+		merged := out[i]
+		for model, u := range merged.Usage {
+			usage[model] = usage[model].Add(u)
+		}
+		messages := merged.MessageCount + s.MessageCount
+		if s.LastActive.After(merged.LastActive) {
+			merged = s
+		}
+		merged.Usage, merged.Sidechains, merged.MessageCount = usage, nil, messages
+		out[i] = merged
+	}
+	return out
+}
+
+func groupKey(s sessions.Session, by GroupBy) string {
+	t := s.LastActive.Local()
+	switch by {
+	case ByDay:
+		return t.Format("2006-01-02")
+	case ByWeek:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case ByProject:
+		return s.Project
+	case ByBranch:
+		if s.GitBranch == "" {
+			return "(none)"
+		}
+		return s.GitBranch
+	}
+	return ""
+}
+
+// WriteTable writes the report as an aligned text table with a total row.
+func WriteTable(w io.Writer, rep Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tSESSIONS\tMESSAGES\tINPUT\tOUTPUT\tCACHE WRITE\tCACHE READ\tCOST\n", headerFor(rep.GroupBy))
+	for _, r := range append(rep.Rows, rep.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			r.Key, r.Sessions, r.Messages,
+			sessions.FormatTokens(r.Tokens.Input),
+			sessions.FormatTokens(r.Tokens.Output),
+			sessions.FormatTokens(r.Tokens.CacheCreation),
+			sessions.FormatTokens(r.Tokens.CacheRead),
+			pricing.FormatUSD(r.Cost))
+	}
+	return tw.Flush()
+}
+
+func headerFor(by GroupBy) string {
+	switch by {
+	case ByDay:
+		return "DAY"
+	case ByWeek:
+		return "WEEK"
+	case ByProject:
+		return "PROJECT"
+	case ByBranch:
+		return "BRANCH"
+	case ByModel:
+		return "MODEL"
+	}
+	return "GROUP"
+}
+
+// jsonRow is the JSON and CSV shape of a Row.
+type jsonRow struct {
+	Key                 string  `json:"key"`
+	Sessions            int     `json:"sessions"`
+	Messages            int     `json:"messages"`
+	InputTokens         int64   `json:"input_tokens"`
+	OutputTokens        int64   `json:"output_tokens"`
+	CacheCreationTokens int64   `json:"cache_creation_tokens"`
+	CacheReadTokens     int64   `json:"cache_read_tokens"`
+	TotalTokens         int64   `json:"total_tokens"`
+	CostUSD             float64 `json:"cost_usd"`
+}
+
+func toJSONRow(r Row) jsonRow {
+	return jsonRow{
+		Key:                 r.Key,
+		Sessions:            r.Sessions,
+		Messages:            r.Messages,
+		InputTokens:         r.Tokens.Input,
+		OutputTokens:        r.Tokens.Output,
+		CacheCreationTokens: r.Tokens.CacheCreation,
+		CacheReadTokens:     r.Tokens.CacheRead,
+		TotalTokens:         r.Tokens.Total(),
+		CostUSD:             r.Cost,
+	}
+}
+
+// WriteJSON writes the report as a JSON object with rows and a total.
+func WriteJSON(w io.Writer, rep Report) error {
+	out := struct {
+		GroupBy GroupBy   `json:"group_by"`
+		Rows    []jsonRow `json:"rows"`
+		Total   jsonRow   `json:"total"`
+	}{GroupBy: rep.GroupBy, Rows: []jsonRow{}, Total: toJSONRow(rep.Total)}
+	for _, r := range rep.Rows {
+		out.Rows = append(out.Rows, toJSONRow(r))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// WriteCSV writes one CSV record per row, without the total.
+func WriteCSV(w io.Writer, rep Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{string(rep.GroupBy), "sessions", "messages", "input_tokens", "output_tokens",
+		"cache_creation_tokens", "cache_read_tokens", "total_tokens", "cost_usd"})
+	for _, r := range rep.Rows {
+		j := toJSONRow(r)
+		cw.Write([]string{
+			j.Key,
+			strconv.Itoa(j.Sessions),
+			strconv.Itoa(j.Messages),
+			strconv.FormatInt(j.InputTokens, 10),
+			strconv.FormatInt(j.OutputTokens, 10),
+			strconv.FormatInt(j.CacheCreationTokens, 10),
+			strconv.FormatInt(j.CacheReadTokens, 10),
+			strconv.FormatInt(j.TotalTokens, 10),
+			strconv.FormatFloat(j.CostUSD, 'f', 4, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
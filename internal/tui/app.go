@@ -5,6 +5,10 @@ import (
 	"path/filepath"
 	"strings"
 
+	"claude-manager/internal/filter"
+	"claude-manager/internal/index"
+	"claude-manager/internal/meta"
+	"claude-manager/internal/pricing"
 	"claude-manager/internal/sessions"
 	"claude-manager/internal/worktree"
 
@@ -19,6 +23,8 @@ type Model struct {
 	filteredSessions []sessions.Session
 	cursor           int
 	search           textinput.Model
+	query            filter.Query // last valid parse of the search box
+	queryErr         error        // why the search box does not parse, if it doesn't
 	searching        bool
 	width            int
 	height           int
@@ -26,35 +32,111 @@ type Model struct {
 	chosen          bool // true when user pressed Enter to resume
 	newSession      bool // true when user pressed n to start new session
 	newSessionPath  string // chosen project path for new session
+	newSessionDir   string // data root directory of the chosen project, if known
 	showNewSession  bool
 	newSessionPaths []projectEntry
 	newSessionCursor int
 	cwd             string // working directory where claude-manager was launched
 	fullTextSearch  bool // true = search all message text, false = summary/project/branch only
+	exactSearch     bool // true = substring matching, false = fuzzy matching ranked by score
 	SkipPermissions bool // pass --dangerously-skip-permissions to claude
 	UseWorktree     bool // resume in a new git worktree
+	Prices          pricing.Table // used to estimate session cost
+	Meta            *meta.Store   // tags, pins and notes; nil disables editing them
+	Roots           []sessions.Root // data roots; with more than one, sessions show their root
 	showWorktrees   bool
 	worktrees       []worktree.Entry
 	worktreeCursor  int
 	worktreeMsg     string // feedback after removal
+	selected        map[string]bool // file paths of multi-selected sessions
+	openChains      map[string]bool   // continuation chains shown expanded, by chain ID
+	chainHeads      map[string]string // file path of the row standing for each chain shown
+	chainFolded     int               // parts of the sessions shown hidden in collapsed chains
+	confirm         batchAction     // action waiting for y/n
+	pending         []sessions.Session // sessions the confirmed action applies to
+	statusMsg       string // feedback shown in the status bar
+	editing         editKind // user data being edited in the prompt
+	editInput       textinput.Model
+	editTargets     []sessions.Session // sessions the edit applies to
+	showTranscript  bool
+	transcript      transcriptView
+	loader          *sessions.Loader
+	changes         <-chan struct{} // signals that session files changed on disk
+	index           *index.Index    // full-text index; nil searches user messages only
+	snippets        map[string]*snippet // full-text matches by file path, for snippetSearch
+	snippetSearch   string
 }
 
 type projectEntry struct {
-	Name string
-	Path string
+	Name      string
+	Path      string
+	ConfigDir string // data root of the sessions the project came from
 }
 
 // NewModel creates a new TUI model with the given sessions.
 func NewModel(ss []sessions.Session, cwd string) Model {
 	ti := textinput.New()
-	ti.Placeholder = "Search... (@repo to filter by project)"
+	ti.Placeholder = "Search... (@repo #tag branch: since:2w msgs:>20 -word a OR b)"
 	ti.CharLimit = 100
 
-	return Model{
-		allSessions:      ss,
-		filteredSessions: ss,
-		search:           ti,
-		cwd:              cwd,
+	m := Model{
+		allSessions: ss,
+		search:      ti,
+		cwd:         cwd,
+		Prices:      pricing.Default(),
+	}
+	m.filteredSessions = m.groupChains(ss)
+	return m
+}
+
+// EnableAutoRefresh makes the model reload sessions through loader whenever
+// changes fires, merging new and updated sessions into the list.
+func (m *Model) EnableAutoRefresh(loader *sessions.Loader, changes <-chan struct{}) {
+	m.loader = loader
+	m.changes = changes
+}
+
+// EnableIndex makes full-text search use idx, which the model keeps in sync
+// with the session list.
+func (m *Model) EnableIndex(idx *index.Index) {
+	m.index = idx
+}
+
+// indexSyncedMsg reports that an index sync finished.
+type indexSyncedMsg struct{}
+
+// syncIndexCmd brings the index up to date with the given sessions.
+func (m Model) syncIndexCmd() tea.Cmd {
+	if m.index == nil {
+		return nil
+	}
+	paths := make([]string, len(m.allSessions))
+	for i, s := range m.allSessions {
+		paths[i] = s.FilePath
+	}
+	idx := m.index
+	return func() tea.Msg {
+		idx.Sync(paths)
+		return indexSyncedMsg{}
+	}
+}
+
+// sessionsUpdatedMsg carries the sessions that changed on disk.
+type sessionsUpdatedMsg struct {
+	update sessions.Update
+}
+
+// waitForChangesCmd blocks until session files change, then refreshes them.
+func waitForChangesCmd(loader *sessions.Loader, changes <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		if _, ok := <-changes; !ok {
+			return nil
+		}
+		upd, _, err := loader.Refresh()
+		if err != nil {
+			return sessionsUpdatedMsg{}
+		}
+		return sessionsUpdatedMsg{update: upd}
 	}
 }
 
@@ -74,22 +156,95 @@ func discoverWorktreesCmd(ss []sessions.Session) tea.Cmd {
 	}
 }
 
-func removeWorktreeCmd(entries []worktree.Entry, idx int) tea.Cmd {
+func removeWorktreeCmd(entries []worktree.Entry, idx int, roots []sessions.Root) tea.Cmd {
 	return func() tea.Msg {
-		err := worktree.Remove(entries[idx])
+		err := worktree.Remove(entries[idx], roots)
 		return worktreeRemovedMsg{idx: idx, err: err}
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.SetWindowTitle("claude-manager")
+	cmds := []tea.Cmd{tea.SetWindowTitle("claude-manager"), m.syncIndexCmd()}
+	if m.changes != nil {
+		cmds = append(cmds, waitForChangesCmd(m.loader, m.changes))
+	}
+	return tea.Batch(cmds...)
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if nm, ok := next.(Model); ok {
+		if load := nm.loadSnippetsCmd(); load != nil {
+			return nm, tea.Batch(cmd, load)
+		}
+		return nm, cmd
+	}
+	return next, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.showTranscript && !m.transcript.loading {
+			m.transcript.layout(m.width - 4)
+		}
+		return m, nil
+
+	case transcriptLoadedMsg:
+		if msg.path != m.transcript.session.FilePath {
+			return m, nil
+		}
+		m.transcript.loading = false
+		m.transcript.err = msg.err
+		m.transcript.width = m.width - 4
+		m.transcript.setTranscript(msg.turns, msg.conv)
+		if m.transcript.jump != nil {
+			m.transcript.jumpTo(m.transcript.jump)
+			m.transcript.scroll(m.transcriptHeight())
+		}
+		return m, nil
+
+	case snippetsLoadedMsg:
+		if msg.search == m.snippetSearch {
+			for path, sn := range msg.snippets {
+				m.snippets[path] = sn
+			}
+		}
+		return m, nil
+
+	case sessionsUpdatedMsg:
+		m.mergeSessions(msg.update)
+		cmd := waitForChangesCmd(m.loader, m.changes)
+		if len(msg.update.Changed) > 0 || len(msg.update.Removed) > 0 {
+			cmd = tea.Batch(cmd, m.syncIndexCmd())
+		}
+		return m, cmd
+
+	case indexSyncedMsg:
+		if (m.fullTextSearch || m.query.SearchesText()) && m.search.Value() != "" {
+			m.resort()
+		}
+		return m, nil
+
+	case batchDoneMsg:
+		var removed []string
+		for _, s := range msg.done {
+			removed = append(removed, s.FilePath)
+		}
+		m.mergeSessions(sessions.Update{Removed: removed})
+		switch {
+		case len(msg.done) == 1 && len(msg.errs) == 0:
+			s := msg.done[0]
+			m.statusMsg = fmt.Sprintf("Archived %s (claude-manager restore %s to undo)", truncate(s.Summary, 40), shortID(s.ID))
+		default:
+			m.statusMsg = batchStatus("Archived", len(msg.done), msg.errs)
+		}
+		return m, nil
+
+	case sessionsExportedMsg:
+		m.statusMsg = batchStatus("Exported", msg.n, msg.errs) + " to " + msg.dir
 		return m, nil
 
 	case worktreesLoadedMsg:
@@ -117,6 +272,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.showWorktrees {
 			return m.handleWorktreeKey(msg)
 		}
+		if m.showTranscript {
+			return m.handleTranscriptKey(msg)
+		}
+		if m.editing != editNone {
+			return m.handleEditKey(msg)
+		}
+		if m.confirm != noAction {
+			return m.handleConfirmKey(msg)
+		}
 		if m.searching {
 			return m.handleSearchKey(msg)
 		}
@@ -145,6 +309,11 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.applyFilters()
 		return m, nil
 
+	case "ctrl+t":
+		m.exactSearch = !m.exactSearch
+		m.applyFilters()
+		return m, nil
+
 	default:
 		var cmd tea.Cmd
 		m.search, cmd = m.search.Update(msg)
@@ -153,7 +322,30 @@ func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+func (m Model) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action, pending := m.confirm, m.pending
+	m.confirm, m.pending = noAction, nil
+	if msg.String() != "y" || len(pending) == 0 {
+		m.statusMsg = ""
+		return m, nil
+	}
+	m.statusMsg = "Working..."
+	return m, runBatchCmd(action, pending)
+}
+
+// askConfirm prompts for y/n before applying action to the current targets.
+func (m *Model) askConfirm(action batchAction) {
+	ss := m.targets()
+	if len(ss) == 0 {
+		return
+	}
+	m.confirm = action
+	m.pending = ss
+	m.statusMsg = fmt.Sprintf("%s %s? (y/n)", action.verb(), describeTargets(ss))
+}
+
 func (m Model) handleNormalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.statusMsg = ""
 	switch msg.String() {
 	case "q", "ctrl+c":
 		return m, tea.Quit
@@ -163,6 +355,10 @@ func (m Model) handleNormalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.showHelp = false
 			return m, nil
 		}
+		if len(m.selected) > 0 {
+			m.selected = nil
+			return m, nil
+		}
 		if m.search.Value() != "" {
 			m.search.SetValue("")
 			m.applyFilters()
@@ -208,6 +404,14 @@ func (m Model) handleNormalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "right", "l":
+		m.toggleChain(true)
+		return m, nil
+
+	case "left", "h":
+		m.toggleChain(false)
+		return m, nil
+
 	case "pgdown":
 		m.cursor += 10
 		if max := len(m.filteredSessions) - 1; m.cursor > max {
@@ -231,6 +435,71 @@ func (m Model) handleNormalKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.worktreeMsg = ""
 		return m, discoverWorktreesCmd(m.allSessions)
 
+	case " ":
+		m.toggleSelected()
+		if m.cursor < len(m.filteredSessions)-1 {
+			m.cursor++
+		}
+		return m, nil
+
+	case "a":
+		m.toggleSelectAll()
+		return m, nil
+
+	case "d", "delete":
+		m.askConfirm(archiveAction)
+		return m, nil
+
+	case "x":
+		if ss := m.targets(); len(ss) > 0 {
+			m.statusMsg = "Exporting..."
+			return m, exportSessionsCmd(ss, m.cwd)
+		}
+		return m, nil
+
+	case "y":
+		if ss := m.targets(); len(ss) > 0 {
+			m.statusMsg = copyIDs(ss)
+		}
+		return m, nil
+
+	case "p":
+		m.togglePin()
+		return m, nil
+
+	case "#":
+		return m, m.startEdit(editTags)
+
+	case "e":
+		return m, m.startEdit(editNote)
+
+	case "r":
+		return m, m.startEdit(editTitle)
+
+	case "v":
+		if m.cursor < len(m.filteredSessions) {
+			s := m.filteredSessions[m.cursor]
+			m.showTranscript = true
+			m.transcript = newTranscriptView(s)
+			return m, loadTranscriptCmd(s.FilePath)
+		}
+		return m, nil
+
+	case "o":
+		if m.cursor < len(m.filteredSessions) {
+			s := m.filteredSessions[m.cursor]
+			sn := m.snippetFor(s)
+			if sn == nil {
+				m.statusMsg = "No matching message to open"
+				return m, nil
+			}
+			m.showTranscript = true
+			m.transcript = newTranscriptView(s)
+			m.transcript.jump = sn
+			return m, loadTranscriptCmd(s.FilePath)
+		}
+		return m, nil
+
 	case "n":
 		m.showNewSession = true
 		m.newSessionCursor = 0
@@ -272,7 +541,7 @@ func (m Model) handleWorktreeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "d", "x":
 		if len(m.worktrees) > 0 && m.worktreeCursor < len(m.worktrees) {
 			m.worktreeMsg = fmt.Sprintf("Removing %s...", m.worktrees[m.worktreeCursor].Path)
-			return m, removeWorktreeCmd(m.worktrees, m.worktreeCursor)
+			return m, removeWorktreeCmd(m.worktrees, m.worktreeCursor, m.Roots)
 		}
 		return m, nil
 	}
@@ -283,24 +552,42 @@ func (m Model) buildProjectList() []projectEntry {
 	seen := map[string]bool{}
 	var entries []projectEntry
 
-	// Current directory first
+	// Current directory first, once per root
 	if m.cwd != "" {
-		entries = append(entries, projectEntry{
-			Name: filepath.Base(m.cwd) + " (current dir)",
-			Path: m.cwd,
-		})
-		seen[m.cwd] = true
+		name := filepath.Base(m.cwd) + " (current dir)"
+		if len(m.Roots) == 0 {
+			entries = append(entries, projectEntry{Name: name, Path: m.cwd})
+			seen[m.cwd] = true
+		}
+		for _, r := range m.Roots {
+			e := projectEntry{Name: name, Path: m.cwd, ConfigDir: r.Dir}
+			key := m.cwd
+			if m.showRoots() {
+				key += "\x00" + r.Dir
+				e.Name += " [" + r.Name + "]"
+			}
+			entries = append(entries, e)
+			seen[key] = true
+		}
 	}
 
-	// Unique project paths from sessions, ordered by most recent
+	// Unique project paths from sessions, ordered by most recent, once per
+	// root when there are several
 	for _, s := range m.allSessions {
-		if s.ProjectPath == "" || seen[s.ProjectPath] {
+		key := s.ProjectPath
+		name := s.Project
+		if m.showRoots() {
+			key += "\x00" + s.ConfigDir
+			name += " [" + s.Root + "]"
+		}
+		if s.ProjectPath == "" || seen[key] {
 			continue
 		}
-		seen[s.ProjectPath] = true
+		seen[key] = true
 		entries = append(entries, projectEntry{
-			Name: s.Project,
-			Path: s.ProjectPath,
+			Name:      name,
+			Path:      s.ProjectPath,
+			ConfigDir: s.ConfigDir,
 		})
 	}
 
@@ -340,6 +627,7 @@ func (m Model) handleNewSessionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if len(m.newSessionPaths) > 0 && m.newSessionCursor < len(m.newSessionPaths) {
 			m.newSession = true
 			m.newSessionPath = m.newSessionPaths[m.newSessionCursor].Path
+			m.newSessionDir = m.newSessionPaths[m.newSessionCursor].ConfigDir
 			return m, tea.Quit
 		}
 		return m, nil
@@ -402,40 +690,104 @@ func (m Model) renderNewSession() string {
 	return b.String()
 }
 
-// parseQuery splits a search query into an optional @project prefix and remaining search text.
-// e.g. "@producthunt some query" -> ("producthunt", "some query")
-//      "just a query"            -> ("", "just a query")
-func parseQuery(raw string) (project, query string) {
-	raw = strings.TrimSpace(raw)
-	if !strings.HasPrefix(raw, "@") {
-		return "", raw
+// applyFilters re-applies the search query. While the query does not parse,
+// as when a quote or parenthesis is still open, the last valid one is used.
+func (m *Model) applyFilters() {
+	q, err := filter.ParseQuery(m.search.Value())
+	m.queryErr = err
+	if err == nil {
+		m.query = q
 	}
-	// Split on first space after @project
-	rest := raw[1:] // strip @
-	if idx := strings.IndexByte(rest, ' '); idx >= 0 {
-		return rest[:idx], strings.TrimSpace(rest[idx+1:])
+	m.filteredSessions = m.groupChains(m.query.Filter(m.allSessions, m.searchMode()))
+	m.cursor = 0
+}
+
+func (m Model) searchMode() filter.Mode {
+	mode := filter.Mode{FullText: m.fullTextSearch, Exact: m.exactSearch}
+	if m.index != nil {
+		mode.Index = m.index
 	}
-	return rest, ""
+	return mode
 }
 
-// applyFilters re-applies @project filter + search query.
-func (m *Model) applyFilters() {
-	project, query := parseQuery(m.search.Value())
-	src := m.allSessions
-	if project != "" {
-		src = filterByProject(src, project)
+// mergeSessions folds an on-disk update into allSessions and reapplies the
+// current filters, keeping the cursor on the same session where possible.
+func (m *Model) mergeSessions(upd sessions.Update) {
+	if len(upd.Changed) == 0 && len(upd.Removed) == 0 {
+		return
 	}
-	m.filteredSessions = filterSessions(src, query, m.fullTextSearch)
-	m.cursor = 0
+
+	byPath := make(map[string]sessions.Session, len(upd.Changed))
+	for _, s := range upd.Changed {
+		byPath[s.FilePath] = s
+	}
+	removed := make(map[string]bool, len(upd.Removed))
+	for _, p := range upd.Removed {
+		removed[p] = true
+	}
+
+	merged := make([]sessions.Session, 0, len(m.allSessions)+len(upd.Changed))
+	for _, s := range m.allSessions {
+		if removed[s.FilePath] {
+			continue
+		}
+		if c, ok := byPath[s.FilePath]; ok {
+			s = c
+			delete(byPath, s.FilePath)
+		}
+		merged = append(merged, s)
+	}
+	for _, s := range upd.Changed {
+		if _, ok := byPath[s.FilePath]; ok {
+			merged = append(merged, s)
+		}
+	}
+	m.allSessions = merged
+	for p := range removed {
+		delete(m.selected, p)
+	}
+	m.resort()
 }
 
-// SelectedSession returns the session the user picked via Enter, or nil if they quit.
+// resort reapplies user data and ordering to allSessions and refilters,
+// keeping the cursor on the same session where possible.
+func (m *Model) resort() {
+	// filteredSessions may share allSessions' backing array, so note the
+	// selected session before sorting.
+	var selectedID, selectedChain string
+	if m.cursor < len(m.filteredSessions) {
+		selectedID = m.filteredSessions[m.cursor].ID
+		selectedChain = m.filteredSessions[m.cursor].ChainID()
+	}
+	if m.Meta != nil {
+		m.Meta.Apply(m.allSessions)
+	}
+	sessions.SortByLastActive(m.allSessions)
+
+	m.applyFilters()
+	for i, s := range m.filteredSessions {
+		if s.ID == selectedID {
+			m.cursor = i
+			return
+		}
+	}
+	// A newer part may now stand for the selected chain.
+	for i, s := range m.filteredSessions {
+		if m.isChainHead(s) && s.ChainID() == selectedChain {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+// SelectedSession returns the session the user picked via Enter, or nil if
+// they quit. For a continuation chain that is its latest part.
 func (m Model) SelectedSession() *sessions.Session {
 	if !m.chosen {
 		return nil
 	}
 	if m.cursor < len(m.filteredSessions) {
-		s := m.filteredSessions[m.cursor]
+		s := sessions.LatestPart(m.allSessions, m.filteredSessions[m.cursor])
 		return &s
 	}
 	return nil
@@ -449,6 +801,18 @@ func (m Model) NewSessionPath() string {
 	return m.newSessionPath
 }
 
+// NewSessionConfigDir returns the data root directory to start the new
+// session in, or "" to keep the environment's.
+func (m Model) NewSessionConfigDir() string {
+	return m.newSessionDir
+}
+
+// showRoots reports whether sessions come from more than one data root and
+// so are labeled with theirs.
+func (m Model) showRoots() bool {
+	return len(m.Roots) > 1
+}
+
 func (m Model) View() string {
 	if m.width == 0 {
 		return "Loading..."
@@ -462,6 +826,10 @@ func (m Model) View() string {
 		return m.renderWorktrees()
 	}
 
+	if m.showTranscript {
+		return m.renderTranscript()
+	}
+
 	if m.showHelp {
 		return m.renderHelp()
 	}
@@ -477,10 +845,17 @@ func (m Model) View() string {
 	modeTag := ""
 	modeTagWidth := 0
 	if m.searching || m.search.Value() != "" {
+		match := "fuzzy"
+		if m.exactSearch {
+			match = "exact"
+		}
 		if m.fullTextSearch {
-			modeTag = lipgloss.NewStyle().Foreground(special).Render(" [full-text]")
+			if m.index != nil && !m.index.Ready() {
+				match += " · indexing"
+			}
+			modeTag = lipgloss.NewStyle().Foreground(special).Render(" [full-text · " + match + "]")
 		} else {
-			modeTag = lipgloss.NewStyle().Foreground(dimText).Render(" [quick]")
+			modeTag = lipgloss.NewStyle().Foreground(dimText).Render(" [quick · " + match + "]")
 		}
 		modeTagWidth = lipgloss.Width(modeTag)
 	}
@@ -489,7 +864,10 @@ func (m Model) View() string {
 		searchBoxWidth = 10
 	}
 	m.search.Width = searchBoxWidth - 4 // account for border + padding
-	if m.searching {
+	if m.editing != editNone {
+		m.editInput.Width = m.width - 8 - lipgloss.Width(m.editInput.Prompt)
+		b.WriteString(searchActiveStyle.Width(m.width - 4).Render(m.editInput.View()))
+	} else if m.searching {
 		searchBox := searchActiveStyle.Width(searchBoxWidth).Render(m.search.View())
 		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Center, searchBox, modeTag))
 	} else if m.search.Value() != "" {
@@ -504,7 +882,17 @@ func (m Model) View() string {
 	headerHeight := 4 // title + search + borders
 	helpBarHeight := 1
 	statusHeight := 1
-	detailHeight := 10
+	detailHeight := 12
+	var details []string
+	if m.cursor < len(m.filteredSessions) {
+		s := m.filteredSessions[m.cursor]
+		details = detailLines(s, m.Prices, m.showRoots())
+		if sn := m.snippetFor(s); sn != nil {
+			details = append(details, m.snippetDetailLines(sn, m.width)...)
+		}
+		// Content plus border and padding.
+		detailHeight = len(details) + 4
+	}
 
 	listHeight := m.height - headerHeight - helpBarHeight - statusHeight - detailHeight - 1
 	if listHeight < 5 {
@@ -520,51 +908,88 @@ func (m Model) View() string {
 			Render("No sessions found")
 		b.WriteString(empty)
 	} else {
-		// Calculate visible window
-		start := 0
-		if m.cursor >= listHeight {
-			start = m.cursor - listHeight + 1
+		// Calculate visible window. Rows with a full-text excerpt take two
+		// lines.
+		rowHeight := func(i int) int {
+			if m.snippetFor(m.filteredSessions[i]) != nil {
+				return 2
+			}
+			return 1
 		}
-		end := start + listHeight
-		if end > len(m.filteredSessions) {
-			end = len(m.filteredSessions)
+		start, used := m.cursor, rowHeight(m.cursor)
+		for start > 0 && used+rowHeight(start-1) <= listHeight {
+			start--
+			used += rowHeight(start)
+		}
+		end, used := start, 0
+		for end < len(m.filteredSessions) && used+rowHeight(end) <= listHeight {
+			used += rowHeight(end)
+			end++
 		}
 
+		var hl func(string) []int
+		if m.search.Value() != "" {
+			mode := m.searchMode()
+			hl = func(text string) []int { return m.query.Highlight(text, mode) }
+		}
 		for i := start; i < end; i++ {
-			selected := i == m.cursor
-			b.WriteString(renderSessionItem(m.filteredSessions[i], m.width, selected))
+			s := m.filteredSessions[i]
+			mark := ""
+			if len(m.selected) > 0 {
+				mark = "  "
+				if m.selected[s.FilePath] {
+					mark = markStyle.Render("● ")
+				}
+			}
+			root := ""
+			if m.showRoots() {
+				root = s.Root
+			}
+			b.WriteString(renderSessionItem(s, mark, root, m.chainPrefix(s), m.width, i == m.cursor, hl))
 			b.WriteString("\n")
+			if sn := m.snippetFor(s); sn != nil {
+				b.WriteString(m.renderSnippetRow(sn, m.width))
+				b.WriteString("\n")
+			}
 		}
 
 		// Pad remaining lines
-		rendered := end - start
-		for i := rendered; i < listHeight; i++ {
+		for i := used; i < listHeight; i++ {
 			b.WriteString("\n")
 		}
 	}
 
 	// Detail panel
 	if len(m.filteredSessions) > 0 && m.cursor < len(m.filteredSessions) && detailHeight > 3 {
-		b.WriteString(renderDetail(m.filteredSessions[m.cursor], m.width, detailHeight))
+		b.WriteString(renderDetail(details, m.width, detailHeight))
 		b.WriteString("\n")
 	}
 
 	// Status bar
 	status := fmt.Sprintf(" %d sessions", len(m.filteredSessions))
-	if len(m.filteredSessions) != len(m.allSessions) {
+	if len(m.filteredSessions)+m.chainFolded != len(m.allSessions) {
 		status += fmt.Sprintf(" (of %d)", len(m.allSessions))
 	}
+	if len(m.selected) > 0 {
+		status += fmt.Sprintf("  %d selected", len(m.selected))
+	}
 	if m.UseWorktree {
 		status += "  🌳 worktree"
 	}
 	if m.SkipPermissions {
 		status += "  ⚡ skip-permissions"
 	}
+	if m.statusMsg != "" {
+		status += "  " + m.statusMsg
+	}
+	if m.queryErr != nil {
+		status += "  Search: " + m.queryErr.Error()
+	}
 	b.WriteString(statusBarStyle.Width(m.width).Render(status))
 	b.WriteString("\n")
 
 	// Help bar
-	help := "↑↓ navigate • enter resume • n new session • w worktree • t worktrees • / search • ! skip-perms • ? help • q quit"
+	help := "↑↓ navigate • enter resume • →/← expand/collapse chain • v view • space select • d archive • p pin • # tag • r rename • n new session • w worktree • t worktrees • / search • ! skip-perms • ? help • q quit"
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
@@ -630,14 +1055,27 @@ func (m Model) renderHelp() string {
 		{"g/Home", "Go to top"},
 		{"G/End", "Go to bottom"},
 		{"PgUp/PgDn", "Page up/down"},
-		{"Enter", "Resume selected session"},
+		{"Enter", "Resume selected session (the latest part of a chain)"},
+		{"→/l ←/h", "Expand/collapse a continuation chain"},
+		{"v", "View full transcript"},
+		{"o", "Open transcript at the full-text match"},
+		{"Space", "Select/deselect session"},
+		{"a", "Select/deselect all shown"},
+		{"d", "Archive selected sessions"},
+		{"x", "Export selected sessions as Markdown"},
+		{"y", "Copy selected session IDs"},
+		{"p", "Pin/unpin selected sessions"},
+		{"#", "Add/remove tags on selected sessions"},
+		{"e", "Edit note"},
+		{"r", "Rename session"},
 		{"n", "New session (choose project)"},
 		{"w", "Toggle worktree mode"},
 		{"t", "Manage worktrees"},
-		{"/", "Search (@repo to filter by project)"},
+		{"/", "Search (@repo #tag branch: path: model: since: before: msgs: id:, -not, OR)"},
 		{"Tab", "Toggle full-text search (in search mode)"},
+		{"Ctrl+T", "Toggle fuzzy/exact matching (in search mode)"},
 		{"!", "Toggle --dangerously-skip-permissions"},
-		{"Esc", "Clear search / close help"},
+		{"Esc", "Clear selection / search, close help"},
 		{"?", "Toggle help"},
 		{"q", "Quit"},
 	}
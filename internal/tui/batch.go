@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"claude-manager/internal/export"
+	"claude-manager/internal/sessions"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// batchAction is an action applied to the selected sessions, or to the
+// session under the cursor when nothing is selected.
+type batchAction int
+
+const (
+	noAction batchAction = iota
+	archiveAction
+)
+
+func (a batchAction) verb() string {
+	switch a {
+	case archiveAction:
+		return "Archive"
+	}
+	return ""
+}
+
+// batchDoneMsg reports the outcome of a batch action.
+type batchDoneMsg struct {
+	action batchAction
+	done   []sessions.Session // sessions the action succeeded on
+	errs   []error
+}
+
+func runBatchCmd(action batchAction, ss []sessions.Session) tea.Cmd {
+	return func() tea.Msg {
+		msg := batchDoneMsg{action: action}
+		for _, s := range ss {
+			var err error
+			switch action {
+			case archiveAction:
+				_, err = sessions.Archive(s)
+			}
+			if err != nil {
+				msg.errs = append(msg.errs, fmt.Errorf("%s: %w", shortID(s.ID), err))
+				continue
+			}
+			msg.done = append(msg.done, s)
+		}
+		return msg
+	}
+}
+
+type sessionsExportedMsg struct {
+	dir  string
+	n    int
+	errs []error
+}
+
+// exportSessionsCmd writes each session's transcript as Markdown into dir,
+// one claude-session-<id>.md file per session.
+func exportSessionsCmd(ss []sessions.Session, dir string) tea.Cmd {
+	return func() tea.Msg {
+		msg := sessionsExportedMsg{dir: dir}
+		for _, s := range ss {
+			if err := exportSession(s, dir); err != nil {
+				msg.errs = append(msg.errs, fmt.Errorf("%s: %w", shortID(s.ID), err))
+				continue
+			}
+			msg.n++
+		}
+		return msg
+	}
+}
+
+func exportSession(s sessions.Session, dir string) error {
+	turns, err := sessions.LoadTranscript(s.FilePath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(dir, "claude-session-"+shortID(s.ID)+".md"))
+	if err != nil {
+		return err
+	}
+	if err := export.Markdown(f, s, turns); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyIDs puts one session ID per line on the clipboard. Without a system
+// clipboard tool (e.g. over SSH) it falls back to the terminal's OSC 52
+// escape sequence, which most modern terminals honor.
+func copyIDs(ss []sessions.Session) string {
+	ids := make([]string, len(ss))
+	for i, s := range ss {
+		ids[i] = s.ID
+	}
+	text := strings.Join(ids, "\n")
+	if err := clipboard.WriteAll(text); err != nil {
+		if _, err := osc52.New(text).WriteTo(os.Stderr); err != nil {
+			return fmt.Sprintf("Error: copy failed: %v", err)
+		}
+	}
+	if len(ids) == 1 {
+		return "Copied " + ids[0]
+	}
+	return fmt.Sprintf("Copied %d session IDs", len(ids))
+}
+
+// targets returns the sessions a batch action applies to: the selection in
+// list order, or the session under the cursor if nothing is selected. The
+// row of a collapsed chain stands for every part of the chain.
+func (m Model) targets() []sessions.Session {
+	picked := m.selected
+	if len(picked) == 0 {
+		if m.cursor >= len(m.filteredSessions) {
+			return nil
+		}
+		picked = map[string]bool{m.filteredSessions[m.cursor].FilePath: true}
+	}
+	chains := make(map[string]bool)
+	for _, s := range m.allSessions {
+		if picked[s.FilePath] && m.isChainHead(s) && !m.openChains[s.ChainID()] {
+			chains[s.ChainID()] = true
+		}
+	}
+	var out []sessions.Session
+	for _, s := range m.allSessions {
+		if picked[s.FilePath] || len(s.Chain) > 0 && chains[s.ChainID()] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// toggleSelected selects or deselects the session under the cursor.
+func (m *Model) toggleSelected() {
+	if m.cursor >= len(m.filteredSessions) {
+		return
+	}
+	path := m.filteredSessions[m.cursor].FilePath
+	if m.selected[path] {
+		delete(m.selected, path)
+		return
+	}
+	if m.selected == nil {
+		m.selected = make(map[string]bool)
+	}
+	m.selected[path] = true
+}
+
+// toggleSelectAll selects every filtered session, or deselects them all if
+// they are already selected.
+func (m *Model) toggleSelectAll() {
+	all := len(m.filteredSessions) > 0
+	for _, s := range m.filteredSessions {
+		if !m.selected[s.FilePath] {
+			all = false
+			break
+		}
+	}
+	if m.selected == nil {
+		m.selected = make(map[string]bool)
+	}
+	for _, s := range m.filteredSessions {
+		if all {
+			delete(m.selected, s.FilePath)
+		} else {
+			m.selected[s.FilePath] = true
+		}
+	}
+}
+
+// describeTargets names a batch's sessions for a confirmation prompt.
+func describeTargets(ss []sessions.Session) string {
+	if len(ss) == 1 {
+		return fmt.Sprintf("%q", truncate(ss[0].Summary, 50))
+	}
+	return fmt.Sprintf("%d sessions", len(ss))
+}
+
+// batchStatus summarizes a batch outcome for the status bar.
+func batchStatus(verb string, n int, errs []error) string {
+	status := fmt.Sprintf("%s %d session(s)", verb, n)
+	if len(errs) > 0 {
+		status += fmt.Sprintf("; Error: %v", errs[0])
+		if len(errs) > 1 {
+			status += fmt.Sprintf(" (+%d more)", len(errs)-1)
+		}
+	}
+	return status
+}
@@ -0,0 +1,239 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-manager/internal/sessions"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// forkNote marks a turn after which the shown conversation forks.
+type forkNote struct {
+	text   string
+	branch int // picker entry to select when the note is opened
+}
+
+// setTranscript stores a loaded transcript and shows its active path.
+func (v *transcriptView) setTranscript(turns []sessions.Turn, conv *sessions.Conversation) {
+	v.all = turns
+	v.conv = conv
+	v.branches = nil
+	if conv != nil {
+		v.branches = conv.Branches()
+	}
+	v.showBranch(0)
+}
+
+// showBranch shows the active conversation (0) or abandoned branch i, from
+// the start of the conversation to the branch's last message.
+func (v *transcriptView) showBranch(i int) {
+	v.branch = i
+	v.turns = v.all
+	if len(v.branches) > 0 {
+		path := v.conv.ActivePath()
+		if i > 0 {
+			path = v.conv.Path(v.branches[i-1].Leaf())
+		}
+		v.turns = v.conv.TurnsOn(v.all, path)
+	}
+
+	v.forks = make(map[int]forkNote)
+	if i == 0 {
+		counts := make(map[int]int)
+		for bi, b := range v.branches {
+			ti := v.turnAt(b.Fork)
+			if ti < 0 {
+				continue
+			}
+			counts[ti]++
+			if _, ok := v.forks[ti]; !ok {
+				v.forks[ti] = forkNote{branch: bi + 1}
+			}
+		}
+		for ti, n := range counts {
+			note := v.forks[ti]
+			note.text = "⑂ an earlier attempt branched off here (b to view)"
+			if n > 1 {
+				note.text = fmt.Sprintf("⑂ %d earlier attempts branched off here (b to view)", n)
+			}
+			v.forks[ti] = note
+		}
+	} else if ti := v.turnAt(v.branches[i-1].Fork); ti >= 0 {
+		v.forks[ti] = forkNote{text: "⑂ this abandoned branch splits off from the active conversation here", branch: 0}
+	}
+
+	v.expanded = make(map[blockRef]bool)
+	v.agents = make(map[int]bool)
+	v.query, v.matches, v.match, v.msg = "", nil, 0, ""
+	v.search.SetValue("")
+	v.cursor, v.offset = 0, 0
+	v.layout(v.width)
+}
+
+// turnAt returns the index of the shown main conversation turn holding the
+// entry n, or -1.
+func (v *transcriptView) turnAt(n *sessions.Node) int {
+	if n == nil {
+		return -1
+	}
+	ti := -1
+	for i, t := range v.turns {
+		if t.Offset > n.Offset {
+			break
+		}
+		if t.Sidechain == 0 {
+			ti = i
+		}
+	}
+	return ti
+}
+
+// showBranchAt switches to the first branch that shows the message at
+// offset, if the current one does not.
+func (v *transcriptView) showBranchAt(offset int64) {
+	var want int64 = -1
+	for _, t := range v.all {
+		if t.Offset > offset {
+			break
+		}
+		want = t.Offset
+	}
+	shows := func(turns []sessions.Turn) bool {
+		for _, t := range turns {
+			if t.Offset == want {
+				return true
+			}
+		}
+		return false
+	}
+	if want < 0 || len(v.branches) == 0 || shows(v.turns) {
+		return
+	}
+	for i := 0; i <= len(v.branches); i++ {
+		path := v.conv.ActivePath()
+		if i > 0 {
+			path = v.conv.Path(v.branches[i-1].Leaf())
+		}
+		if shows(v.conv.TurnsOn(v.all, path)) {
+			v.showBranch(i)
+			return
+		}
+	}
+}
+
+// openPicker shows the branch picker with entry i selected.
+func (v *transcriptView) openPicker(i int) {
+	if len(v.branches) == 0 {
+		v.msg = "No abandoned branches"
+		return
+	}
+	v.picking = true
+	v.pick = i
+}
+
+func (m Model) handleBranchPickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.transcript
+	switch msg.String() {
+	case "esc", "b":
+		v.picking = false
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if v.pick > 0 {
+			v.pick--
+		}
+	case "down", "j":
+		if v.pick < len(v.branches) {
+			v.pick++
+		}
+	case "enter":
+		v.picking = false
+		v.showBranch(v.pick)
+		if v.pick > 0 {
+			// Start where the branch splits off.
+			if ti := v.turnAt(v.branches[v.pick-1].Fork); ti >= 0 {
+				v.moveToTurn(ti)
+			}
+			v.scroll(m.transcriptHeight())
+		}
+	}
+	return m, nil
+}
+
+// branchLabel describes picker entry i: the active conversation or an
+// abandoned branch, with where it splits off and how it starts.
+func (v *transcriptView) branchLabel(i int) string {
+	if i == 0 {
+		path := v.conv.ActivePath()
+		n := 0
+		for _, node := range path {
+			if node.IsMessage() {
+				n++
+			}
+		}
+		label := fmt.Sprintf("Active conversation · %d messages", n)
+		if leaf := v.conv.Leaf; leaf != nil && !leaf.Timestamp.IsZero() {
+			label += " · " + leaf.Timestamp.Local().Format("Jan 2 15:04")
+		}
+		return label
+	}
+
+	b := v.branches[i-1]
+	label := fmt.Sprintf("Branch %d · %d messages", i, b.Messages())
+	first := b.FirstMessage()
+	if !first.Timestamp.IsZero() {
+		label += " · " + first.Timestamp.Local().Format("Jan 2 15:04")
+	}
+	after := "at the start"
+	for p := b.Fork; p != nil; p = p.Parent {
+		if p.Text != "" {
+			after = "after " + fmt.Sprintf("%q", truncate(strings.Join(strings.Fields(p.Text), " "), 30))
+			break
+		}
+	}
+	label += " · " + after
+	if first.Text != "" {
+		who := "You"
+		if first.Type == "assistant" {
+			who = "Claude"
+		}
+		label += " · " + who + ": " + strings.Join(strings.Fields(first.Text), " ")
+	}
+	return label
+}
+
+// renderBranchPicker renders the picker in place of the transcript lines.
+func (m Model) renderBranchPicker(height int) string {
+	v := m.transcript
+	var b strings.Builder
+	b.WriteString(transcriptHeaderStyle.Render("  Branches — earlier attempts abandoned by editing, rewinding or retrying"))
+	b.WriteString("\n\n")
+	lines := 2
+
+	// Keep the selected entry in view.
+	start := 0
+	if rows := height - lines; v.pick >= rows {
+		start = v.pick - rows + 1
+	}
+	for i := start; i <= len(v.branches) && lines < height; i++ {
+		marker := "○ "
+		if i == v.branch {
+			marker = "● "
+		}
+		text := truncate(marker+v.branchLabel(i), m.width-6)
+		if i == v.pick {
+			b.WriteString(transcriptCursorStyle.Render("▌") + " " + lipgloss.NewStyle().Bold(true).Render(text))
+		} else {
+			b.WriteString("  " + summaryStyle.Render(text))
+		}
+		b.WriteString("\n")
+		lines++
+	}
+	for ; lines < height; lines++ {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+
+	"claude-manager/internal/sessions"
+)
+
+// groupChains collapses the continuation chains among ss into one row each,
+// placed where the first of its parts in ss is and showing that part, so a
+// search shows the part that matched best. An expanded chain lists its other
+// parts in ss after it. Resuming the row still resumes the latest part.
+func (m *Model) groupChains(ss []sessions.Session) []sessions.Session {
+	m.chainHeads = make(map[string]string)
+	m.chainFolded = 0
+	parts := make(map[string][]sessions.Session)
+	for _, s := range ss {
+		if len(s.Chain) > 0 {
+			parts[s.ChainID()] = append(parts[s.ChainID()], s)
+		}
+	}
+	out := make([]sessions.Session, 0, len(ss))
+	for _, s := range ss {
+		if len(s.Chain) == 0 {
+			out = append(out, s)
+			continue
+		}
+		id := s.ChainID()
+		if _, ok := m.chainHeads[id]; ok {
+			continue
+		}
+		m.chainHeads[id] = s.FilePath
+		if m.openChains[id] {
+			out = append(out, parts[id]...)
+		} else {
+			out = append(out, s)
+			m.chainFolded += len(parts[id]) - 1
+		}
+	}
+	return out
+}
+
+// isChainHead reports whether s is the row that stands for its chain.
+func (m Model) isChainHead(s sessions.Session) bool {
+	return len(s.Chain) > 0 && m.chainHeads[s.ChainID()] == s.FilePath
+}
+
+// toggleChain expands or collapses the chain of the session under the
+// cursor. Collapsing from one of its parts moves the cursor to the chain's
+// row.
+func (m *Model) toggleChain(open bool) {
+	if m.cursor >= len(m.filteredSessions) {
+		return
+	}
+	s := m.filteredSessions[m.cursor]
+	if len(s.Chain) == 0 || m.openChains[s.ChainID()] == open {
+		return
+	}
+	if m.openChains == nil {
+		m.openChains = make(map[string]bool)
+	}
+	m.openChains[s.ChainID()] = open
+	head := m.chainHeads[s.ChainID()]
+	m.applyFilters()
+	for i, o := range m.filteredSessions {
+		if o.FilePath == head {
+			m.cursor = i
+			break
+		}
+	}
+}
+
+// chainPrefix returns what goes before the summary of a chain's row or of
+// one of its parts, e.g. "⛓3 " or "  ↳ 2/3 ".
+func (m Model) chainPrefix(s sessions.Session) string {
+	if len(s.Chain) == 0 {
+		return ""
+	}
+	if m.isChainHead(s) {
+		return fmt.Sprintf("⛓%d ", len(s.Chain))
+	}
+	return fmt.Sprintf("  ↳ %d/%d ", chainIndex(s)+1, len(s.Chain))
+}
+
+// chainIndex returns the position of s in its chain, oldest first.
+func chainIndex(s sessions.Session) int {
+	for i, id := range s.Chain {
+		if id == s.ID {
+			return i
+		}
+	}
+	return 0
+}
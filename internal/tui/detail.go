@@ -2,18 +2,32 @@ package tui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"claude-manager/internal/pricing"
 	"claude-manager/internal/sessions"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-// renderDetail renders the detail panel for a session.
-func renderDetail(s sessions.Session, width, height int) string {
+// renderDetail renders the detail panel with the given content lines.
+func renderDetail(lines []string, width, height int) string {
 	if width < 30 {
 		return ""
 	}
 
+	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	return detailBorderStyle.
+		Width(width - 4).
+		Height(height - 4).
+		Render(content)
+}
+
+// detailLines returns the content lines of the detail panel. showRoot adds
+// the data root the session came from.
+func detailLines(s sessions.Session, prices pricing.Table, showRoot bool) []string {
 	row := func(label, value string) string {
 		return fmt.Sprintf("%s %s",
 			detailLabelStyle.Render(label),
@@ -29,13 +43,82 @@ func renderDetail(s sessions.Session, width, height int) string {
 		row("Branch:", s.GitBranch),
 		row("Last active:", s.LastActive.Local().Format("Jan 2 15:04") + " (" + s.TimeAgo() + ")"),
 		row("Messages:", fmt.Sprintf("%d", s.MessageCount)),
+		row("Tokens:", formatUsage(s.TotalUsage())),
+		row("Cost:", formatCost(s, prices)),
 		row("Session ID:", s.ID),
 	}
+	if len(s.Chain) > 0 {
+		lines = append(lines, row("Chain:", fmt.Sprintf("part %d of %d", chainIndex(s)+1, len(s.Chain))))
+	}
+	lines = append(lines, sidechainLines(s)...)
+	if showRoot {
+		lines = append(lines, row("Root:", s.Root+" ("+s.ConfigDir+")"))
+	}
+	if s.Title != "" {
+		lines = append(lines, row("Original:", truncate(s.OriginalSummary, 100)))
+	}
+	if len(s.Tags) > 0 {
+		lines = append(lines, row("Tags:", renderTags(s.Tags)))
+	}
+	if s.Note != "" {
+		lines = append(lines, row("Note:", s.Note))
+	}
+	return lines
+}
 
-	content := lipgloss.JoinVertical(lipgloss.Left, lines...)
+// maxSidechainLines caps the sub-agents listed in the detail panel.
+const maxSidechainLines = 5
 
-	return detailBorderStyle.
-		Width(width - 4).
-		Height(height - 4).
-		Render(content)
+// sidechainLines returns a "Sub-agents:" row per sub-agent thread with its
+// own message and token counts.
+func sidechainLines(s sessions.Session) []string {
+	var lines []string
+	label := detailLabelStyle.Render("Sub-agents:")
+	for i, c := range s.Sidechains {
+		if c.MessageCount == 0 {
+			continue
+		}
+		if len(lines) == maxSidechainLines {
+			lines = append(lines, detailLabelStyle.Render("")+" "+timeStyle.Render(fmt.Sprintf("… %d more", len(s.Sidechains)-i)))
+			break
+		}
+		value := fmt.Sprintf("%d msgs", c.MessageCount)
+		if total := c.TotalUsage().Total(); total > 0 {
+			value += " · " + sessions.FormatTokens(total) + " tokens"
+		}
+		if c.Prompt != "" {
+			value += " · " + truncate(strings.Join(strings.Fields(c.Prompt), " "), 60)
+		}
+		lines = append(lines, label+" "+detailValueStyle.Render(fmt.Sprintf("%d. %s", i+1, value)))
+		label = detailLabelStyle.Render("")
+	}
+	return lines
+}
+
+// formatUsage renders a token breakdown, e.g. "12.3k in · 1.2k out · 50.0k cache write · 200.0k cache read".
+func formatUsage(u sessions.TokenUsage) string {
+	if u.Total() == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%s in · %s out · %s cache write · %s cache read",
+		sessions.FormatTokens(u.Input),
+		sessions.FormatTokens(u.Output),
+		sessions.FormatTokens(u.CacheCreation),
+		sessions.FormatTokens(u.CacheRead),
+	)
+}
+
+// formatCost renders the estimated cost, sub-agents included, followed by
+// the models used.
+func formatCost(s sessions.Session, prices pricing.Table) string {
+	usage := s.AllUsage()
+	if len(usage) == 0 {
+		return "—"
+	}
+	models := make([]string, 0, len(usage))
+	for model := range usage {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return fmt.Sprintf("~%s (%s)", pricing.FormatUSD(prices.Cost(usage)), strings.Join(models, ", "))
 }
@@ -3,19 +3,27 @@ package tui
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"claude-manager/internal/sessions"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
-// renderSessionItem renders a single session row.
-func renderSessionItem(s sessions.Session, width int, selected bool) string {
-	project := projectStyle.Render(truncate(s.Project, 16))
+// renderSessionItem renders a single session row. mark is a selection gutter
+// shown before the project, or "" when nothing is multi-selected. root, if
+// not "", labels the data root the session came from. chain goes before the
+// summary of continuation chain rows. hl, if not nil, returns the rune
+// positions of a field to highlight as search matches.
+func renderSessionItem(s sessions.Session, mark, root, chain string, width int, selected bool, hl func(string) []int) string {
+	project := renderField(s.Project, "", 16, projectStyle.UnsetWidth(), hl)
+	if pad := 18 - lipgloss.Width(project); pad > 0 {
+		project += strings.Repeat(" ", pad)
+	}
 
 	branch := ""
 	if s.GitBranch != "" {
-		branch = branchStyle.Render(truncate(s.GitBranch, 30))
+		branch = renderField(s.GitBranch, "", 30, branchStyle, hl)
 	}
 
 	timeAgo := timeStyle.Render(s.TimeAgo())
@@ -23,14 +31,24 @@ func renderSessionItem(s sessions.Session, width int, selected bool) string {
 	// Calculate remaining width for summary
 	// project(18) + branch(~32) + time(~10) + padding(~8)
 	rightSide := fmt.Sprintf(" %s  %s", branch, timeAgo)
-	summaryWidth := width - 18 - lipgloss.Width(rightSide) - 6
+	if len(s.Tags) > 0 {
+		rightSide = " " + tagStyle.Render(truncate(renderTags(s.Tags), 30)) + rightSide
+	}
+	if root != "" {
+		rightSide = " " + rootStyle.Render("["+truncate(root, 16)+"]") + rightSide
+	}
+	summaryWidth := width - 18 - lipgloss.Width(mark) - lipgloss.Width(rightSide) - 6
 	if summaryWidth < 20 {
 		summaryWidth = 20
 	}
 
-	summary := summaryStyle.Render(truncate(s.Summary, summaryWidth))
+	prefix := chain
+	if s.Pinned {
+		prefix += "📌 "
+	}
+	summary := renderField(s.Summary, prefix, summaryWidth, summaryStyle, hl)
 
-	line := fmt.Sprintf("%s %s%s", project, summary, rightSide)
+	line := fmt.Sprintf("%s%s %s%s", mark, project, summary, rightSide)
 
 	if selected {
 		return selectedItemStyle.Render(line)
@@ -38,44 +56,75 @@ func renderSessionItem(s sessions.Session, width int, selected bool) string {
 	return itemStyle.Render(line)
 }
 
-// filterSessions returns sessions matching the query (case-insensitive substring match).
-// When fullText is true, also searches all user message text.
-func filterSessions(all []sessions.Session, query string, fullText bool) []sessions.Session {
-	if query == "" {
-		return all
+// renderField truncates prefix+text to maxLen and renders it in base, with
+// the positions hl reports for text highlighted.
+func renderField(text, prefix string, maxLen int, base lipgloss.Style, hl func(string) []int) string {
+	display := truncate(prefix+text, maxLen)
+	if hl == nil {
+		return base.Render(display)
 	}
-	q := strings.ToLower(query)
-	var result []sessions.Session
-	for _, s := range all {
-		if strings.Contains(strings.ToLower(s.Summary), q) ||
-			strings.Contains(strings.ToLower(s.Project), q) ||
-			strings.Contains(strings.ToLower(s.GitBranch), q) {
-			result = append(result, s)
-		} else if fullText && strings.Contains(strings.ToLower(s.MessageText), q) {
-			result = append(result, s)
+	// Skip the prefix, and the "..." if truncate added one.
+	offset := utf8.RuneCountInString(prefix)
+	limit := utf8.RuneCountInString(display)
+	if display != prefix+text {
+		limit -= 3
+	}
+	var positions []int
+	for _, p := range hl(text) {
+		if p+offset < limit {
+			positions = append(positions, p+offset)
 		}
 	}
-	return result
+	return highlightRunes(display, positions, base)
 }
 
-// filterByProject returns sessions whose project name contains the given substring.
-func filterByProject(all []sessions.Session, project string) []sessions.Session {
-	p := strings.ToLower(project)
-	var result []sessions.Session
-	for _, s := range all {
-		if strings.Contains(strings.ToLower(s.Project), p) {
-			result = append(result, s)
+// highlightRunes renders text in base with the runes at the given ascending
+// positions in fuzzyMatchStyle.
+func highlightRunes(text string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(text)
+	}
+	hi := fuzzyMatchStyle.Inherit(base)
+	var b, run strings.Builder
+	inMatch, next := false, 0
+	flush := func() {
+		if run.Len() == 0 {
+			return
+		}
+		if inMatch {
+			b.WriteString(hi.Render(run.String()))
+		} else {
+			b.WriteString(base.Render(run.String()))
 		}
+		run.Reset()
 	}
-	return result
+	i := 0
+	for _, r := range text {
+		match := next < len(positions) && positions[next] == i
+		if match {
+			next++
+		}
+		if match != inMatch {
+			flush()
+			inMatch = match
+		}
+		run.WriteRune(r)
+		i++
+	}
+	flush()
+	return b.String()
 }
 
+// truncate is sessions.Truncate; rune positions before the cut stay valid.
 func truncate(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	if maxLen <= 3 {
-		return s[:maxLen]
+	return sessions.Truncate(s, maxLen)
+}
+
+// shortID returns the first 8 characters of a session ID, enough to resume it
+// by prefix.
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
 	}
-	return s[:maxLen-3] + "..."
+	return id
 }
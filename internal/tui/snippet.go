@@ -0,0 +1,217 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"claude-manager/internal/filter"
+	"claude-manager/internal/index"
+	"claude-manager/internal/sessions"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Excerpt size, in runes, before and after the start of the match.
+const (
+	snippetBefore = 40
+	snippetAfter  = 240
+)
+
+// snippet is a message that made a session match a full-text search.
+type snippet struct {
+	role   string
+	ts     time.Time
+	text   string // excerpt around the match, whitespace collapsed
+	offset int64  // line offset of the message in the session file
+	term   string // the query word or phrase found in the message
+}
+
+// snippetsLoadedMsg carries the snippets found for the sessions of a search.
+type snippetsLoadedMsg struct {
+	search   string
+	snippets map[string]*snippet // by file path; nil when no message matched
+}
+
+// showSnippets reports whether the current search looks in message content.
+func (m Model) showSnippets() bool {
+	return m.fullTextSearch && m.search.Value() != "" && len(m.query.Terms()) > 0
+}
+
+// loadSnippetsCmd finds snippets for the sessions around the cursor that do
+// not have one yet.
+func (m *Model) loadSnippetsCmd() tea.Cmd {
+	if !m.showSnippets() {
+		return nil
+	}
+	if m.snippets == nil || m.snippetSearch != m.search.Value() {
+		m.snippets = make(map[string]*snippet)
+		m.snippetSearch = m.search.Value()
+	}
+
+	lo, hi := m.cursor-m.height, m.cursor+m.height
+	if lo < 0 {
+		lo = 0
+	}
+	if hi > len(m.filteredSessions) {
+		hi = len(m.filteredSessions)
+	}
+	var paths []string
+	for _, s := range m.filteredSessions[lo:hi] {
+		if _, ok := m.snippets[s.FilePath]; !ok {
+			m.snippets[s.FilePath] = nil // loading
+			paths = append(paths, s.FilePath)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	idx := m.index
+	search, terms := m.search.Value(), m.query.Terms()
+	return func() tea.Msg {
+		return snippetsLoadedMsg{search: search, snippets: findSnippets(idx, terms, paths)}
+	}
+}
+
+// findSnippets returns, for each path, the first message containing one of
+// terms, trying the terms in order. Without an index, files are scanned.
+func findSnippets(idx *index.Index, terms, paths []string) map[string]*snippet {
+	out := make(map[string]*snippet, len(paths))
+	for _, path := range paths {
+		out[path] = nil
+	}
+	for _, term := range terms {
+		missing := make(map[string]bool)
+		for path, sn := range out {
+			if sn == nil {
+				missing[path] = true
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+
+		if idx != nil {
+			hits, ok := idx.Search(term)
+			if ok {
+				for _, h := range hits {
+					if !missing[h.File] {
+						continue
+					}
+					delete(missing, h.File)
+					if msg, err := sessions.ReadMessage(h.File, h.Offset); err == nil {
+						out[h.File] = newSnippet(msg, term)
+					}
+				}
+				continue
+			}
+		}
+
+		q := strings.ToLower(term)
+		for path := range missing {
+			sessions.ScanMessages(path, func(msg sessions.Message) bool {
+				if strings.Contains(strings.ToLower(msg.Text), q) {
+					out[path] = newSnippet(msg, term)
+					return false
+				}
+				return true
+			})
+		}
+	}
+	return out
+}
+
+// newSnippet cuts an excerpt of msg around the first occurrence of term, or
+// of its first word, falling back to the start of the message.
+func newSnippet(msg sessions.Message, term string) *snippet {
+	text := []rune(strings.Join(strings.Fields(msg.Text), " "))
+	lower := []rune(strings.ToLower(string(text)))
+	start := 0
+	if len(lower) == len(text) {
+		needles := []string{term}
+		if words := strings.Fields(term); len(words) > 1 {
+			needles = append(needles, words[0])
+		}
+		for _, needle := range needles {
+			if i := strings.Index(string(lower), strings.ToLower(needle)); i >= 0 {
+				start = len([]rune(string(lower)[:i]))
+				break
+			}
+		}
+	}
+
+	from, to := start-snippetBefore, start+snippetAfter
+	prefix, suffix := "…", "…"
+	if from <= 0 {
+		from, prefix = 0, ""
+	}
+	if to >= len(text) {
+		to, suffix = len(text), ""
+	}
+	return &snippet{
+		role:   msg.Role,
+		ts:     msg.Timestamp,
+		text:   prefix + string(text[from:to]) + suffix,
+		offset: msg.Offset,
+		term:   term,
+	}
+}
+
+// snippetFor returns the loaded snippet of s, or nil.
+func (m Model) snippetFor(s sessions.Session) *snippet {
+	if !m.showSnippets() || m.snippetSearch != m.search.Value() {
+		return nil
+	}
+	return m.snippets[s.FilePath]
+}
+
+// label describes who wrote the message and when, e.g. "Claude · Oct 15 09:00".
+func (sn *snippet) label() string {
+	who := "You"
+	if sn.role == "assistant" {
+		who = "Claude"
+	}
+	if sn.ts.IsZero() {
+		return who
+	}
+	return who + " · " + sn.ts.Local().Format("Jan 2 15:04")
+}
+
+// renderSnippet renders text, cut to width, with the query's words
+// highlighted.
+func (m Model) renderSnippet(text string, width int, base lipgloss.Style) string {
+	if runes := []rune(text); len(runes) > width {
+		text = string(runes[:width-1]) + "…"
+	}
+	return highlightRunes(text, m.query.Highlight(text, filter.Mode{Exact: true}), base)
+}
+
+// renderSnippetRow renders the excerpt line shown under a list row.
+func (m Model) renderSnippetRow(sn *snippet, width int) string {
+	label := sn.label() + ": "
+	avail := width - 10 - len([]rune(label))
+	if avail < 10 {
+		avail = 10
+	}
+	return itemStyle.Render("  ↳ " + snippetLabelStyle.Render(label) + m.renderSnippet(sn.text, avail, snippetStyle))
+}
+
+// snippetDetailLines returns the "Match:" rows of the detail panel: the
+// message's author and time, then the excerpt wrapped to a few lines.
+func (m Model) snippetDetailLines(sn *snippet, width int) []string {
+	lines := []string{detailLabelStyle.Render("Match:") + " " + detailValueStyle.Render(sn.label()+"  (o to open)")}
+	w := width - 12
+	if w < 20 {
+		w = 20
+	}
+	wrapped := wrapText(sn.text, w)
+	if len(wrapped) > 3 {
+		wrapped = wrapped[:3]
+		wrapped[2] = strings.TrimSuffix(wrapped[2], "…") + "…"
+	}
+	for _, l := range wrapped {
+		lines = append(lines, "  "+m.renderSnippet(l, w, snippetStyle))
+	}
+	return lines
+}
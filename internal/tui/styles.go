@@ -4,11 +4,12 @@ import "github.com/charmbracelet/lipgloss"
 
 var (
 	// Colors
-	subtle    = lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"}
-	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
-	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
-	dimText   = lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}
-	white     = lipgloss.AdaptiveColor{Light: "#1A1A1A", Dark: "#FAFAFA"}
+	subtle     = lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"}
+	highlight  = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
+	special    = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
+	dimText    = lipgloss.AdaptiveColor{Light: "#A49FA5", Dark: "#777777"}
+	white      = lipgloss.AdaptiveColor{Light: "#1A1A1A", Dark: "#FAFAFA"}
+	errorColor = lipgloss.AdaptiveColor{Light: "#D7263D", Dark: "#FF5F87"}
 
 	// Title bar
 	titleStyle = lipgloss.NewStyle().
@@ -52,6 +53,17 @@ var (
 	timeStyle = lipgloss.NewStyle().
 			Foreground(dimText)
 
+	tagStyle = lipgloss.NewStyle().
+			Foreground(highlight)
+
+	markStyle = lipgloss.NewStyle().
+			Foreground(special).
+			Bold(true)
+
+	rootStyle = lipgloss.NewStyle().
+			Foreground(dimText).
+			Bold(true)
+
 	// Detail panel
 	detailBorderStyle = lipgloss.NewStyle().
 				Border(lipgloss.RoundedBorder()).
@@ -65,6 +77,44 @@ var (
 	detailValueStyle = lipgloss.NewStyle().
 				Foreground(white)
 
+	// Full-text search excerpts
+	snippetStyle = lipgloss.NewStyle().
+			Foreground(dimText).
+			Italic(true)
+
+	snippetLabelStyle = lipgloss.NewStyle().
+				Foreground(dimText).
+				Bold(true)
+
+	// Transcript viewer
+	transcriptHeaderStyle = lipgloss.NewStyle().
+				Foreground(highlight).
+				Bold(true)
+
+	transcriptAgentStyle = lipgloss.NewStyle().
+				Foreground(special).
+				Bold(true)
+
+	transcriptForkStyle = lipgloss.NewStyle().
+				Foreground(highlight).
+				Italic(true)
+
+	transcriptThinkingStyle = lipgloss.NewStyle().
+				Foreground(dimText).
+				Italic(true)
+
+	transcriptCursorStyle = lipgloss.NewStyle().
+				Foreground(highlight)
+
+	fuzzyMatchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#F5D547")).
+			Bold(true).
+			Underline(true)
+
+	matchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#1A1A1A")).
+			Background(lipgloss.Color("#F5D547"))
+
 	// Help bar
 	helpStyle = lipgloss.NewStyle().
 			Foreground(dimText).
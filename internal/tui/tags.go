@@ -0,0 +1,141 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-manager/internal/meta"
+	"claude-manager/internal/sessions"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editKind is the user data being edited in the prompt.
+type editKind int
+
+const (
+	editNone editKind = iota
+	editTags
+	editNote
+	editTitle
+)
+
+// startEdit opens the prompt for editing tags, the note or the title of the
+// current targets. Notes and titles are edited one session at a time.
+func (m *Model) startEdit(kind editKind) tea.Cmd {
+	if m.Meta == nil {
+		m.statusMsg = "Error: tags, pins and notes are unavailable"
+		return nil
+	}
+	ss := m.targets()
+	if kind != editTags && m.cursor < len(m.filteredSessions) {
+		ss = []sessions.Session{m.filteredSessions[m.cursor]}
+	}
+	if len(ss) == 0 {
+		return nil
+	}
+
+	ti := textinput.New()
+	ti.CharLimit = 500
+	switch kind {
+	case editTags:
+		ti.Prompt = fmt.Sprintf("Tags for %s: ", describeTargets(ss))
+		ti.Placeholder = "tag to add, -tag to remove"
+	case editNote:
+		ti.Prompt = "Note: "
+		ti.SetValue(ss[0].Note)
+	case editTitle:
+		ti.Prompt = "Title: "
+		ti.Placeholder = "empty to restore the original summary"
+		ti.SetValue(ss[0].Summary)
+	}
+	ti.Focus()
+
+	m.editing = kind
+	m.editInput = ti
+	m.editTargets = ss
+	return textinput.Blink
+}
+
+func (m Model) handleEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.editing = editNone
+		m.editTargets = nil
+		return m, nil
+
+	case "enter":
+		kind, ss, value := m.editing, m.editTargets, m.editInput.Value()
+		m.editing = editNone
+		m.editTargets = nil
+		for _, s := range ss {
+			e := m.Meta.Get(s.ID)
+			switch kind {
+			case editTags:
+				e.Tags = meta.EditTags(e.Tags, value)
+			case editNote:
+				e.Note = value
+			case editTitle:
+				e.Title = value
+				if s.Title == "" && value == s.Summary {
+					e.Title = ""
+				}
+			}
+			m.Meta.Set(s.ID, e)
+		}
+		m.saveMeta(fmt.Sprintf("Updated %s", describeTargets(ss)))
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.editInput, cmd = m.editInput.Update(msg)
+	return m, cmd
+}
+
+// togglePin pins the current targets, or unpins them if all are pinned.
+func (m *Model) togglePin() {
+	if m.Meta == nil {
+		m.statusMsg = "Error: tags, pins and notes are unavailable"
+		return
+	}
+	ss := m.targets()
+	if len(ss) == 0 {
+		return
+	}
+	pin := false
+	for _, s := range ss {
+		if !s.Pinned {
+			pin = true
+			break
+		}
+	}
+	for _, s := range ss {
+		e := m.Meta.Get(s.ID)
+		e.Pinned = pin
+		m.Meta.Set(s.ID, e)
+	}
+	verb := "Pinned"
+	if !pin {
+		verb = "Unpinned"
+	}
+	m.saveMeta(fmt.Sprintf("%s %s", verb, describeTargets(ss)))
+}
+
+// saveMeta writes the store, reapplies it to the list and reports status.
+func (m *Model) saveMeta(status string) {
+	if err := m.Meta.Save(); err != nil {
+		status = fmt.Sprintf("Error: %v", err)
+	}
+	m.statusMsg = status
+	m.resort()
+}
+
+// renderTags renders tags as "#a #b".
+func renderTags(tags []string) string {
+	out := make([]string, len(tags))
+	for i, t := range tags {
+		out[i] = "#" + t
+	}
+	return strings.Join(out, " ")
+}
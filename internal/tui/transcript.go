@@ -0,0 +1,730 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"claude-manager/internal/sessions"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type lineKind int
+
+const (
+	lineHeader lineKind = iota
+	lineText
+	lineThinking
+	lineTool
+	lineToolBody
+	lineError
+	lineBlank
+	lineAgent
+	lineFork
+)
+
+// transcriptLine is one rendered row of the transcript viewer.
+type transcriptLine struct {
+	text  string
+	kind  lineKind
+	turn  int
+	block int // -1 for turn headers and spacing
+	agent int // on a sub-agent's summary line, the thread it folds
+	fork  int // on a fork note, the branch picker entry it opens
+}
+
+// blockRef identifies a content block by turn and block index.
+type blockRef struct {
+	turn, block int
+}
+
+// transcriptView holds the state of the full transcript screen.
+type transcriptView struct {
+	session   sessions.Session
+	all       []sessions.Turn // every turn in the file
+	turns     []sessions.Turn // the turns of the branch shown
+	conv      *sessions.Conversation
+	branches  []sessions.Branch // abandoned branches, oldest first
+	branch    int               // branch shown: 0 for the active conversation, else 1 + index into branches
+	forks     map[int]forkNote  // by turn index, where the shown conversation forks
+	picking   bool              // the branch picker is open
+	pick      int               // picker entry selected
+	loading   bool
+	err       error
+	expanded  map[blockRef]bool // foldable blocks the user has opened
+	agents    map[int]bool      // sub-agent threads the user has opened
+	lines     []transcriptLine
+	width     int
+	cursor    int
+	offset    int
+	search    textinput.Model
+	searching bool
+	query     string
+	matches   []blockRef
+	match     int
+	msg       string
+	jump      *snippet // message to show once loaded, if opened from a search match
+}
+
+type transcriptLoadedMsg struct {
+	path  string
+	turns []sessions.Turn
+	conv  *sessions.Conversation // nil if the tree could not be read
+	err   error
+}
+
+func loadTranscriptCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		turns, err := sessions.LoadTranscript(path)
+		conv, _ := sessions.LoadConversation(path)
+		return transcriptLoadedMsg{path: path, turns: turns, conv: conv, err: err}
+	}
+}
+
+func newTranscriptView(s sessions.Session) transcriptView {
+	ti := textinput.New()
+	ti.Placeholder = "Search transcript..."
+	ti.CharLimit = 100
+	return transcriptView{
+		session:  s,
+		loading:  true,
+		expanded: make(map[blockRef]bool),
+		agents:   make(map[int]bool),
+		forks:    make(map[int]forkNote),
+		search:   ti,
+	}
+}
+
+// foldable reports whether a block is collapsed to a single line by default.
+func foldable(b sessions.Block) bool {
+	return b.Kind != sessions.BlockText
+}
+
+// layout rebuilds the rendered lines for the given content width.
+func (v *transcriptView) layout(width int) {
+	if width < 20 {
+		width = 20
+	}
+	v.width = width
+	v.lines = v.lines[:0]
+
+	// Turns are shown in file order, except that the turns of a sub-agent
+	// thread, which may interleave with others, are kept together under a
+	// summary line that folds the thread, placed where the thread starts.
+	agentTurns := make(map[int][]int)
+	for ti, t := range v.turns {
+		if t.Sidechain > 0 {
+			agentTurns[t.Sidechain] = append(agentTurns[t.Sidechain], ti)
+		}
+	}
+	var order []int
+	for ti, t := range v.turns {
+		if n := t.Sidechain; n == 0 {
+			order = append(order, ti)
+		} else if agentTurns[n][0] == ti {
+			order = append(order, agentTurns[n]...)
+		}
+	}
+
+	for _, ti := range order {
+		t := v.turns[ti]
+		gutter := ""
+		if n := t.Sidechain; n > 0 {
+			if agentTurns[n][0] == ti {
+				if len(v.lines) > 0 {
+					v.lines = append(v.lines, transcriptLine{kind: lineBlank, turn: ti, block: -1})
+				}
+				v.lines = append(v.lines, transcriptLine{text: truncate(v.agentSummary(n, len(agentTurns[n])), width), kind: lineAgent, turn: ti, block: -1, agent: n})
+			}
+			if !v.agents[n] {
+				continue
+			}
+			gutter = "│ "
+		}
+
+		who := "You"
+		if t.Role == "assistant" {
+			who = "Claude"
+		}
+		if t.Sidechain > 0 {
+			who = fmt.Sprintf("Claude → sub-agent %d", t.Sidechain)
+			if t.Role == "assistant" {
+				who = fmt.Sprintf("Sub-agent %d", t.Sidechain)
+			}
+		}
+		header := "── " + who
+		if !t.Timestamp.IsZero() {
+			header += " · " + t.Timestamp.Local().Format("Jan 2 15:04:05")
+		}
+		if prev := len(v.lines) - 1; prev >= 0 && (v.lines[prev].kind != lineAgent || v.lines[prev].agent != t.Sidechain) {
+			v.lines = append(v.lines, transcriptLine{text: strings.TrimRight(gutter, " "), kind: lineBlank, turn: ti, block: -1})
+		}
+		v.lines = append(v.lines, transcriptLine{text: gutter + header + " ──", kind: lineHeader, turn: ti, block: -1})
+
+		for bi, b := range t.Blocks {
+			ref := blockRef{ti, bi}
+			add := func(text string, kind lineKind, indent string) {
+				indent = gutter + indent
+				for _, l := range wrapText(text, width-len([]rune(indent))) {
+					v.lines = append(v.lines, transcriptLine{text: indent + l, kind: kind, turn: ti, block: bi})
+				}
+			}
+
+			if !foldable(b) {
+				add(b.Text, lineText, "")
+				continue
+			}
+
+			open := v.expanded[ref]
+			marker := "▸ "
+			if open {
+				marker = "▾ "
+			}
+			bodyKind := lineToolBody
+			if b.IsError {
+				bodyKind = lineError
+			}
+
+			switch b.Kind {
+			case sessions.BlockToolUse:
+				summary := marker + b.ToolName
+				if !open {
+					summary += " " + strings.Join(strings.Fields(b.ToolInput), " ")
+				}
+				v.lines = append(v.lines, transcriptLine{text: gutter + truncate(summary, width-len([]rune(gutter))), kind: lineTool, turn: ti, block: bi})
+				if open {
+					add(b.ToolInput, lineToolBody, "    ")
+				}
+			case sessions.BlockToolResult:
+				label := "result"
+				if b.IsError {
+					label = "error"
+				}
+				n := strings.Count(b.Text, "\n") + 1
+				if b.Text == "" {
+					n = 0
+				}
+				v.lines = append(v.lines, transcriptLine{text: fmt.Sprintf("%s%s%s (%d lines)", gutter, marker, label, n), kind: lineTool, turn: ti, block: bi})
+				if open {
+					add(b.Text, bodyKind, "    ")
+				}
+			case sessions.BlockThinking:
+				v.lines = append(v.lines, transcriptLine{text: gutter + marker + "thinking", kind: lineThinking, turn: ti, block: bi})
+				if open {
+					add(b.Text, lineThinking, "    ")
+				}
+			}
+		}
+
+		if note, ok := v.forks[ti]; ok {
+			v.lines = append(v.lines, transcriptLine{text: truncate(note.text, width), kind: lineFork, turn: ti, block: -1, fork: note.branch})
+		}
+	}
+
+	if v.cursor >= len(v.lines) {
+		v.cursor = len(v.lines) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+// agentSummary is the line that folds sub-agent thread n, e.g.
+// "▸ Sub-agent 1 · 12 turns · 48.2k tokens · Find every caller of parse".
+func (v *transcriptView) agentSummary(n, turns int) string {
+	marker := "▸ "
+	if v.agents[n] {
+		marker = "▾ "
+	}
+	s := fmt.Sprintf("%sSub-agent %d · %d turns", marker, n, turns)
+	if n <= len(v.session.Sidechains) {
+		c := v.session.Sidechains[n-1]
+		if total := c.TotalUsage().Total(); total > 0 {
+			s += " · " + sessions.FormatTokens(total) + " tokens"
+		}
+		if c.Prompt != "" {
+			s += " · " + strings.Join(strings.Fields(c.Prompt), " ")
+		}
+	}
+	return s
+}
+
+// wrapText splits text into lines no wider than width.
+func wrapText(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	text = strings.ReplaceAll(text, "\t", "    ")
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if lipgloss.Width(line) <= width {
+			out = append(out, line)
+			continue
+		}
+		wrapped := lipgloss.NewStyle().Width(width).Render(line)
+		for _, w := range strings.Split(wrapped, "\n") {
+			out = append(out, strings.TrimRight(w, " "))
+		}
+	}
+	return out
+}
+
+// toggleFold opens or closes the foldable block or sub-agent thread under
+// the cursor.
+func (v *transcriptView) toggleFold() {
+	if v.cursor >= len(v.lines) {
+		return
+	}
+	l := v.lines[v.cursor]
+	if l.agent > 0 {
+		v.agents[l.agent] = !v.agents[l.agent]
+		v.layout(v.width)
+		v.moveToAgent(l.agent)
+		return
+	}
+	if l.block < 0 || !foldable(v.turns[l.turn].Blocks[l.block]) {
+		return
+	}
+	ref := blockRef{l.turn, l.block}
+	v.expanded[ref] = !v.expanded[ref]
+	v.layout(v.width)
+	v.moveToBlock(ref, "")
+}
+
+// toggleAll opens every foldable block and sub-agent thread, or closes them
+// all if any is open.
+func (v *transcriptView) toggleAll() {
+	anyOpen := false
+	for _, open := range v.expanded {
+		if open {
+			anyOpen = true
+			break
+		}
+	}
+	for _, open := range v.agents {
+		if open {
+			anyOpen = true
+			break
+		}
+	}
+	v.expanded = make(map[blockRef]bool)
+	v.agents = make(map[int]bool)
+	if !anyOpen {
+		for ti, t := range v.turns {
+			if t.Sidechain > 0 {
+				v.agents[t.Sidechain] = true
+			}
+			for bi, b := range t.Blocks {
+				if foldable(b) {
+					v.expanded[blockRef{ti, bi}] = true
+				}
+			}
+		}
+	}
+	var turn int
+	if v.cursor < len(v.lines) {
+		turn = v.lines[v.cursor].turn
+	}
+	v.layout(v.width)
+	v.moveToTurn(turn)
+}
+
+// moveToBlock puts the cursor on the first line of ref containing query, or
+// on the block's first line if query is empty or not visible.
+func (v *transcriptView) moveToBlock(ref blockRef, query string) {
+	first := -1
+	q := strings.ToLower(query)
+	for i, l := range v.lines {
+		if l.turn != ref.turn || l.block != ref.block {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		if q != "" && strings.Contains(strings.ToLower(l.text), q) {
+			v.cursor = i
+			return
+		}
+	}
+	if first >= 0 {
+		v.cursor = first
+	}
+}
+
+// moveToTurn puts the cursor on the header of turn, or on the summary line
+// of its sub-agent thread if that is folded.
+func (v *transcriptView) moveToTurn(turn int) {
+	for i, l := range v.lines {
+		if l.turn == turn && l.kind == lineHeader {
+			v.cursor = i
+			return
+		}
+	}
+	if turn < len(v.turns) && v.turns[turn].Sidechain > 0 {
+		v.moveToAgent(v.turns[turn].Sidechain)
+	}
+}
+
+// moveToAgent puts the cursor on the summary line of sub-agent thread n.
+func (v *transcriptView) moveToAgent(n int) {
+	for i, l := range v.lines {
+		if l.agent == n {
+			v.cursor = i
+			return
+		}
+	}
+}
+
+// reveal opens the sub-agent thread turn belongs to, if it is folded.
+func (v *transcriptView) reveal(turn int) {
+	if n := v.turns[turn].Sidechain; n > 0 && !v.agents[n] {
+		v.agents[n] = true
+		v.layout(v.width)
+	}
+}
+
+// jumpTo moves to the turn containing sn's message, switching to the branch
+// it is on, and searches it for the matched term.
+func (v *transcriptView) jumpTo(sn *snippet) {
+	v.showBranchAt(sn.offset)
+	turn := -1
+	for i, t := range v.turns {
+		if t.Offset > sn.offset {
+			break
+		}
+		turn = i
+	}
+	if turn < 0 {
+		return
+	}
+	v.reveal(turn)
+	v.moveToTurn(turn)
+	v.search.SetValue(sn.term)
+	v.runSearch(sn.term)
+	if len(v.matches) == 0 || v.matches[v.match].turn != turn {
+		v.moveToTurn(turn)
+	}
+}
+
+// jumpUserPrompt moves to the next (dir > 0) or previous user prompt.
+func (v *transcriptView) jumpUserPrompt(dir int) {
+	cur := 0
+	if v.cursor < len(v.lines) {
+		cur = v.lines[v.cursor].turn
+	}
+	for t := cur + dir; t >= 0 && t < len(v.turns); t += dir {
+		if v.turns[t].IsUserPrompt() {
+			v.moveToTurn(t)
+			return
+		}
+	}
+}
+
+// runSearch finds every block containing the query and jumps to the first
+// match at or after the cursor.
+func (v *transcriptView) runSearch(query string) {
+	v.query = query
+	v.matches = nil
+	v.match = 0
+	if query == "" {
+		v.msg = ""
+		return
+	}
+	q := strings.ToLower(query)
+	for ti, t := range v.turns {
+		for bi, b := range t.Blocks {
+			content := b.Text + "\n" + b.ToolName + "\n" + b.ToolInput
+			if strings.Contains(strings.ToLower(content), q) {
+				v.matches = append(v.matches, blockRef{ti, bi})
+			}
+		}
+	}
+	if len(v.matches) == 0 {
+		v.msg = fmt.Sprintf("No matches for %q", query)
+		return
+	}
+	cur := 0
+	if v.cursor < len(v.lines) {
+		cur = v.lines[v.cursor].turn
+	}
+	for i, ref := range v.matches {
+		if ref.turn >= cur {
+			v.match = i
+			break
+		}
+	}
+	v.showMatch()
+}
+
+// nextMatch cycles through search matches in the given direction.
+func (v *transcriptView) nextMatch(dir int) {
+	if len(v.matches) == 0 {
+		return
+	}
+	v.match = (v.match + dir + len(v.matches)) % len(v.matches)
+	v.showMatch()
+}
+
+// showMatch unfolds the current match and moves the cursor onto it.
+func (v *transcriptView) showMatch() {
+	ref := v.matches[v.match]
+	v.reveal(ref.turn)
+	if foldable(v.turns[ref.turn].Blocks[ref.block]) && !v.expanded[ref] {
+		v.expanded[ref] = true
+		v.layout(v.width)
+	}
+	v.moveToBlock(ref, v.query)
+	v.msg = fmt.Sprintf("Match %d of %d", v.match+1, len(v.matches))
+}
+
+// scroll keeps the cursor inside a viewport of the given height.
+func (v *transcriptView) scroll(height int) {
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	if v.cursor >= v.offset+height {
+		v.offset = v.cursor - height + 1
+	}
+	if v.offset < 0 {
+		v.offset = 0
+	}
+}
+
+func (m Model) transcriptHeight() int {
+	h := m.height - 5 // title + blank + status + help + spacing
+	if h < 3 {
+		h = 3
+	}
+	return h
+}
+
+func (m Model) handleTranscriptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := &m.transcript
+
+	if v.searching {
+		switch msg.String() {
+		case "esc":
+			v.searching = false
+			v.search.Blur()
+			return m, nil
+		case "enter":
+			v.searching = false
+			v.search.Blur()
+			v.runSearch(strings.TrimSpace(v.search.Value()))
+			v.scroll(m.transcriptHeight())
+			return m, nil
+		default:
+			var cmd tea.Cmd
+			v.search, cmd = v.search.Update(msg)
+			return m, cmd
+		}
+	}
+
+	if v.picking {
+		return m.handleBranchPickerKey(msg)
+	}
+
+	height := m.transcriptHeight()
+	switch msg.String() {
+	case "esc":
+		if v.query != "" {
+			v.runSearch("")
+			v.search.SetValue("")
+			return m, nil
+		}
+		if v.branch > 0 {
+			v.showBranch(0)
+			return m, nil
+		}
+		m.showTranscript = false
+		return m, nil
+
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if v.cursor > 0 {
+			v.cursor--
+		}
+
+	case "down", "j":
+		if v.cursor < len(v.lines)-1 {
+			v.cursor++
+		}
+
+	case "home", "g":
+		v.cursor = 0
+
+	case "end", "G":
+		v.cursor = len(v.lines) - 1
+
+	case "pgup":
+		v.cursor -= height
+		if v.cursor < 0 {
+			v.cursor = 0
+		}
+
+	case "pgdown":
+		v.cursor += height
+		if v.cursor > len(v.lines)-1 {
+			v.cursor = len(v.lines) - 1
+		}
+
+	case "enter", " ", "tab":
+		if v.cursor < len(v.lines) && v.lines[v.cursor].kind == lineFork {
+			v.openPicker(v.lines[v.cursor].fork)
+			return m, nil
+		}
+		v.toggleFold()
+
+	case "b":
+		v.openPicker(v.branch)
+		return m, nil
+
+	case "e":
+		v.toggleAll()
+
+	case "u":
+		v.jumpUserPrompt(1)
+
+	case "U":
+		v.jumpUserPrompt(-1)
+
+	case "/":
+		v.searching = true
+		v.search.Focus()
+		return m, textinput.Blink
+
+	case "n":
+		v.nextMatch(1)
+
+	case "N":
+		v.nextMatch(-1)
+	}
+
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+	v.scroll(height)
+	return m, nil
+}
+
+func (m Model) renderTranscript() string {
+	v := m.transcript
+	var b strings.Builder
+	b.WriteString(titleStyle.Width(m.width).Render(" claude-manager — " + truncate(v.session.Summary, m.width-24)))
+	b.WriteString("\n\n")
+
+	height := m.transcriptHeight()
+	switch {
+	case v.loading:
+		b.WriteString(lipgloss.NewStyle().Foreground(dimText).Padding(0, 2).Render("Loading transcript..."))
+		b.WriteString(strings.Repeat("\n", height))
+	case v.err != nil:
+		b.WriteString(lipgloss.NewStyle().Foreground(errorColor).Padding(0, 2).Render(fmt.Sprintf("Error: %v", v.err)))
+		b.WriteString(strings.Repeat("\n", height))
+	case v.picking:
+		b.WriteString(m.renderBranchPicker(height))
+	default:
+		end := v.offset + height
+		if end > len(v.lines) {
+			end = len(v.lines)
+		}
+		for i := v.offset; i < end; i++ {
+			l := v.lines[i]
+			text := highlightMatches(l.text, v.query, transcriptLineStyle(l.kind))
+			if i == v.cursor {
+				b.WriteString(transcriptCursorStyle.Render("▌") + " " + text)
+			} else {
+				b.WriteString("  " + text)
+			}
+			b.WriteString("\n")
+		}
+		for i := end - v.offset; i < height; i++ {
+			b.WriteString("\n")
+		}
+	}
+
+	if v.searching {
+		b.WriteString(" / " + v.search.View())
+	} else {
+		main, agents := 0, 0
+		for _, t := range v.turns {
+			if t.Sidechain == 0 {
+				main++
+			} else if t.Sidechain > agents {
+				agents = t.Sidechain
+			}
+		}
+		status := fmt.Sprintf(" %d turns", main)
+		if agents > 0 {
+			status += fmt.Sprintf(" · %d sub-agents", agents)
+		}
+		if v.branch > 0 {
+			status = fmt.Sprintf(" Abandoned branch %d of %d ·", v.branch, len(v.branches)) + status
+		} else if n := len(v.branches); n == 1 {
+			status += " · 1 abandoned branch"
+		} else if n > 1 {
+			status += fmt.Sprintf(" · %d abandoned branches", n)
+		}
+		if v.msg != "" {
+			status += "  " + v.msg
+		}
+		b.WriteString(statusBarStyle.Width(m.width).Render(status))
+	}
+	b.WriteString("\n")
+	if v.picking {
+		b.WriteString(helpStyle.Render("↑↓ select • enter view branch • b/Esc cancel"))
+	} else {
+		b.WriteString(helpStyle.Render("↑↓ scroll • enter fold/sub-agent • e fold all • u/U next/prev prompt • / search • n/N next/prev match • b branches • Esc back"))
+	}
+	return b.String()
+}
+
+func transcriptLineStyle(k lineKind) lipgloss.Style {
+	switch k {
+	case lineHeader:
+		return transcriptHeaderStyle
+	case lineAgent:
+		return transcriptAgentStyle
+	case lineFork:
+		return transcriptForkStyle
+	case lineThinking:
+		return transcriptThinkingStyle
+	case lineTool:
+		return branchStyle
+	case lineToolBody:
+		return timeStyle
+	case lineError:
+		return lipgloss.NewStyle().Foreground(errorColor)
+	default:
+		return summaryStyle
+	}
+}
+
+// highlightMatches renders text in base with every case-insensitive
+// occurrence of query highlighted.
+func highlightMatches(text, query string, base lipgloss.Style) string {
+	if query == "" {
+		return base.Render(text)
+	}
+	lower := strings.ToLower(text)
+	q := strings.ToLower(query)
+	if len(lower) != len(text) {
+		// Case folding changed byte offsets; fall back to no highlighting.
+		return base.Render(text)
+	}
+	var b strings.Builder
+	for {
+		idx := strings.Index(lower, q)
+		if idx < 0 {
+			b.WriteString(base.Render(text))
+			return b.String()
+		}
+		b.WriteString(base.Render(text[:idx]))
+		b.WriteString(matchStyle.Render(text[idx : idx+len(q)]))
+		text, lower = text[idx+len(q):], lower[idx+len(q):]
+	}
+}
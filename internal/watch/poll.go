@@ -0,0 +1,65 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// startPolling rescans root every pollInterval and calls poke when the set of
+// session files or any of their sizes or mtimes differ from the last scan.
+func startPolling(root string, poke func()) func() error {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		prev := snapshot(root)
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			cur := snapshot(root)
+			if !sameSnapshot(prev, cur) {
+				poke()
+			}
+			prev = cur
+		}
+	}()
+	return func() error {
+		close(done)
+		return nil
+	}
+}
+
+func snapshot(root string) map[string]fileStamp {
+	files, _ := filepath.Glob(filepath.Join(root, "*", "*.jsonl"))
+	snap := make(map[string]fileStamp, len(files))
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		snap[f] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+	}
+	return snap
+}
+
+func sameSnapshot(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, sa := range a {
+		sb, ok := b[path]
+		if !ok || sa.size != sb.size || !sa.modTime.Equal(sb.modTime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+// Package watch reports changes to session files under Claude projects
+// directories. It uses inotify on Linux and falls back to polling elsewhere or
+// when inotify is unavailable.
+package watch
+
+import (
+	"sync"
+	"time"
+)
+
+// debounce is how long the watcher waits for a burst of writes to settle
+// before signalling a change. Active sessions append several lines per turn.
+const debounce = 300 * time.Millisecond
+
+// pollInterval is how often the polling fallback rescans the directory.
+const pollInterval = 2 * time.Second
+
+// Watcher signals on Changes whenever session files are created, modified or
+// removed. Bursts of events are coalesced into a single signal.
+type Watcher struct {
+	changes chan struct{}
+	events  chan struct{}
+	done    chan struct{}
+	stop    func() error
+	once    sync.Once
+}
+
+// New starts watching roots, which are expected to be projects directories
+// such as ~/.claude/projects.
+func New(roots ...string) *Watcher {
+	w := &Watcher{
+		changes: make(chan struct{}, 1),
+		events:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	var stops []func() error
+	for _, root := range roots {
+		stop, err := startNative(root, w.poke)
+		if err != nil {
+			stop = startPolling(root, w.poke)
+		}
+		stops = append(stops, stop)
+	}
+	w.stop = func() error {
+		var first error
+		for _, stop := range stops {
+			if err := stop(); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+	go w.debounceLoop()
+	return w
+}
+
+// Changes returns a channel that receives a value after files under the
+// watched roots change. It is closed when the watcher is closed.
+func (w *Watcher) Changes() <-chan struct{} {
+	return w.changes
+}
+
+// Close stops watching and closes the Changes channel.
+func (w *Watcher) Close() error {
+	var err error
+	w.once.Do(func() {
+		err = w.stop()
+		close(w.done)
+	})
+	return err
+}
+
+// poke records that something changed without blocking the event source.
+func (w *Watcher) poke() {
+	select {
+	case w.events <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Watcher) debounceLoop() {
+	defer close(w.changes)
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-w.events:
+		}
+
+		// Wait for the burst to settle.
+		timer := time.NewTimer(debounce)
+	settle:
+		for {
+			select {
+			case <-w.done:
+				timer.Stop()
+				return
+			case <-w.events:
+				timer.Reset(debounce)
+			case <-timer.C:
+				break settle
+			}
+		}
+
+		select {
+		case w.changes <- struct{}{}:
+		default:
+		}
+	}
+}
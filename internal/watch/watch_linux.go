@@ -0,0 +1,88 @@
+//go:build linux
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// rootMask watches for project directories being added to the root.
+	rootMask = syscall.IN_CREATE | syscall.IN_MOVED_TO | syscall.IN_ONLYDIR
+	// projectMask watches for session files being written, added or removed.
+	projectMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+		syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO
+)
+
+// startNative watches root and each project directory beneath it with
+// inotify, adding watches for project directories created later on.
+func startNative(root string, poke func()) (func() error, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	// A non-blocking fd lets os.File use the runtime poller, so Close
+	// unblocks the pending Read below.
+	f := os.NewFile(uintptr(fd), "inotify")
+
+	rootWd, err := syscall.InotifyAddWatch(fd, root, rootMask)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			addProjectWatch(fd, filepath.Join(root, e.Name()))
+		}
+	}
+
+	go func() {
+		buf := make([]byte, 64*1024)
+		for {
+			n, err := f.Read(buf)
+			if err != nil {
+				return
+			}
+			changed := false
+			for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+				ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+				nameBytes := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+				name := strings.TrimRight(string(nameBytes), "\x00")
+				off += syscall.SizeofInotifyEvent + int(ev.Len)
+
+				if int(ev.Wd) == rootWd {
+					if ev.Mask&syscall.IN_ISDIR != 0 {
+						addProjectWatch(fd, filepath.Join(root, name))
+						changed = true
+					}
+					continue
+				}
+				if strings.HasSuffix(name, ".jsonl") {
+					changed = true
+				}
+			}
+			if changed {
+				poke()
+			}
+		}
+	}()
+
+	return f.Close, nil
+}
+
+// addProjectWatch watches a project directory. Failures are ignored: the
+// directory may already be gone, and its sessions are still picked up by the
+// next refresh triggered from another directory.
+func addProjectWatch(fd int, dir string) {
+	syscall.InotifyAddWatch(fd, dir, projectMask)
+}
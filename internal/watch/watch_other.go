@@ -0,0 +1,10 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// startNative is only implemented on Linux; other platforms poll.
+func startNative(root string, poke func()) (func() error, error) {
+	return nil, errors.New("native file watching not supported on this platform")
+}
@@ -41,22 +41,21 @@ func Discover(ss []sessions.Session) []Entry {
 	return entries
 }
 
-// Remove removes a worktree via git and cleans up the Claude session directory.
-func Remove(e Entry) error {
+// Remove removes a worktree via git and cleans up its Claude project
+// directory in each of roots.
+func Remove(e Entry, roots []sessions.Root) error {
 	cmd := exec.Command("git", "-C", e.RepoRoot, "worktree", "remove", e.Path)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("%s: %s", err, strings.TrimSpace(string(out)))
 	}
 
-	// Clean up ~/.claude/projects/<encoded-path>/
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil // worktree removed, cleanup is best-effort
-	}
-	encoded := strings.ReplaceAll(e.Path, "/", "-")
-	projectDir := filepath.Join(homeDir, ".claude", "projects", encoded)
-	if _, err := os.Stat(projectDir); err == nil {
-		os.RemoveAll(projectDir)
+	// Clean up <root>/projects/<encoded-path>/; this is best-effort.
+	encoded := sessions.EncodeProjectPath(e.Path)
+	for _, r := range roots {
+		projectDir := filepath.Join(r.ProjectsDir(), encoded)
+		if _, err := os.Stat(projectDir); err == nil {
+			os.RemoveAll(projectDir)
+		}
 	}
 	return nil
 }
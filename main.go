@@ -1,6 +1,8 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,63 +10,193 @@ import (
 	"strings"
 	"syscall"
 	"text/tabwriter"
-
+	"time"
+
+	"claude-manager/internal/config"
+	"claude-manager/internal/export"
+	"claude-manager/internal/filter"
+	"claude-manager/internal/index"
+	"claude-manager/internal/listing"
+	"claude-manager/internal/meta"
+	"claude-manager/internal/prune"
 	"claude-manager/internal/sessions"
+	"claude-manager/internal/stats"
 	"claude-manager/internal/tui"
+	"claude-manager/internal/watch"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// cfg is the user config, loaded once at startup.
+var cfg config.Config
+
+// roots are the Claude data directories sessions are loaded from.
+var roots []sessions.Root
+
 func main() {
-	// Parse flags: "!" for skip-permissions, "w" for worktree mode
+	// Parse flags before the subcommand: "!" for skip-permissions, "w" for
+	// worktree mode, and --root (repeatable) for the data roots. Everything
+	// from the subcommand on is passed through untouched.
 	var skipPerms, useWorktree bool
-	var rest []string
-	for _, a := range os.Args[1:] {
-		switch a {
-		case "!":
+	var rest, rootSpecs []string
+	args := os.Args[1:]
+	for i := 0; i < len(args) && rest == nil; i++ {
+		a := args[i]
+		switch {
+		case a == "!":
 			skipPerms = true
-		case "w":
+		case a == "w":
 			useWorktree = true
+		case a == "--root":
+			if i+1 == len(args) {
+				fmt.Fprintln(os.Stderr, "Error: --root needs a directory")
+				os.Exit(1)
+			}
+			i++
+			rootSpecs = append(rootSpecs, args[i])
+		case strings.HasPrefix(a, "--root="):
+			rootSpecs = append(rootSpecs, strings.TrimPrefix(a, "--root="))
 		default:
-			rest = append(rest, a)
+			rest = args[i:]
 		}
 	}
+	cfg = loadConfig()
+	roots = resolveRoots(rootSpecs)
 
 	switch {
 	case len(rest) == 0:
 		runTUI(skipPerms, useWorktree)
 	case rest[0] == "list":
-		runList()
+		runList(rest[1:])
 	case rest[0] == "resume" && len(rest) >= 2:
-		runResume(rest[1])
+		runResume(strings.Join(rest[1:], " "), skipPerms, useWorktree)
+	case rest[0] == "stats":
+		runStats(rest[1:])
+	case rest[0] == "export":
+		runExport(rest[1:])
+	case rest[0] == "rm" && len(rest) >= 2:
+		runRm(rest[1:])
+	case rest[0] == "restore" && len(rest) >= 2:
+		runRestore(rest[1:])
+	case rest[0] == "rename" && len(rest) >= 3:
+		runRename(rest[1], strings.Join(rest[2:], " "))
+	case rest[0] == "prune":
+		runPrune(rest[1:])
 	default:
-		fmt.Fprintf(os.Stderr, "Usage: claude-manager [! w] [list | resume <session-id>]\n")
+		fmt.Fprintf(os.Stderr, "Usage: claude-manager [! w] [--root [name=]dir ...] [list [flags] | resume <id-prefix|last[@project]|summary text> | stats [flags] | export <session> [flags] | rm <session...> | restore <session...> | rename <session> <title> | prune [flags]]\n")
 		os.Exit(1)
 	}
 }
 
+// loadConfig reads the user config, warning and falling back to defaults if
+// it cannot be parsed.
+func loadConfig() config.Config {
+	c, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring config: %v\n", err)
+	}
+	return c
+}
+
+// resolveRoots returns the data roots given by --root flags, else by the
+// config file, else by CLAUDE_CONFIG_DIR or ~/.claude.
+func resolveRoots(specs []string) []sessions.Root {
+	if len(specs) == 0 {
+		specs = cfg.Roots
+	}
+	if len(specs) == 0 {
+		rs, err := sessions.DefaultRoots()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return rs
+	}
+	var rs []sessions.Root
+	for _, spec := range specs {
+		r, err := sessions.ParseRoot(spec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		rs = append(rs, r)
+	}
+	return rs
+}
+
+// newLoader returns a session loader for the selected roots.
+func newLoader() *sessions.Loader {
+	l := sessions.NewLoader()
+	l.Roots = roots
+	return l
+}
+
+// projectsDirs returns the projects directory of every root.
+func projectsDirs() []string {
+	dirs := make([]string, len(roots))
+	for i, r := range roots {
+		dirs[i] = r.ProjectsDir()
+	}
+	return dirs
+}
+
 func loadSessions() []sessions.Session {
-	ss, err := sessions.LoadAll()
+	return loadSessionsWith(newLoader(), loadMeta())
+}
+
+// loadMeta opens the tag, pin and note store. If it cannot be read it warns
+// and returns nil, and sessions are shown without that data.
+func loadMeta() *meta.Store {
+	store, err := meta.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring tags, pins and notes: %v\n", err)
+		return nil
+	}
+	return store
+}
+
+func loadSessionsWith(loader *sessions.Loader, store *meta.Store) []sessions.Session {
+	ss, fileErrs, err := loader.Load()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading sessions: %v\n", err)
 		os.Exit(1)
 	}
+	for _, fe := range fileErrs {
+		fmt.Fprintf(os.Stderr, "Warning: skipping %v\n", fe)
+	}
 	if len(ss) == 0 {
-		fmt.Fprintln(os.Stderr, "No sessions found in ~/.claude/projects/")
+		fmt.Fprintf(os.Stderr, "No sessions found in %s\n", strings.Join(projectsDirs(), ", "))
 		os.Exit(0)
 	}
+	if store != nil {
+		store.Apply(ss)
+		sessions.SortByLastActive(ss)
+	}
 	return ss
 }
 
 func runTUI(skipPerms, useWorktree bool) {
-	ss := loadSessions()
+	loader := newLoader()
+	store := loadMeta()
+	ss := loadSessionsWith(loader, store)
 	cwd, _ := os.Getwd()
 	m := tui.NewModel(ss, cwd)
+	m.Meta = store
+	m.Roots = roots
 	m.SkipPermissions = skipPerms
 	m.UseWorktree = useWorktree
+	m.Prices = cfg.PriceTable()
+
+	w := watch.New(projectsDirs()...)
+	defer w.Close()
+	m.EnableAutoRefresh(loader, w.Changes())
+	idx := index.Open()
+	m.EnableIndex(idx)
 
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	result, err := p.Run()
+	loader.Save()
+	idx.Save()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -73,10 +205,11 @@ func runTUI(skipPerms, useWorktree bool) {
 	final := result.(tui.Model)
 
 	if path := final.NewSessionPath(); path != "" {
+		dir := final.NewSessionConfigDir()
 		if final.UseWorktree {
-			worktreeNewSession(path, final.SkipPermissions)
+			worktreeNewSession(path, dir, final.SkipPermissions)
 		} else {
-			startNewSession(path, final.SkipPermissions)
+			startNewSession(path, dir, final.SkipPermissions)
 		}
 		return
 	}
@@ -93,34 +226,378 @@ func runTUI(skipPerms, useWorktree bool) {
 	}
 }
 
-func runList() {
+func runList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	format := fs.String("format", "table", "output format: "+strings.Join(listing.Formats, ", "))
+	fieldList := fs.String("fields", "", "comma-separated fields to output (default depends on format)")
+	project := fs.String("project", "", "only sessions whose project name contains this")
+	branch := fs.String("branch", "", "only sessions whose git branch contains this")
+	path := fs.String("path", "", "only sessions whose project path starts with this")
+	since := fs.String("since", "", "only sessions active since, e.g. 3d, 2w, 2025-01-01")
+	until := fs.String("until", "", "only sessions active before, e.g. 1d, 2025-02-01")
+	minMessages := fs.Int("min-messages", 0, "only sessions with at least this many messages")
+	grep := fs.String("grep", "", "only sessions with a message containing these words")
+	tag := fs.String("tag", "", "only sessions with this tag")
+	query := fs.String("query", "", `only sessions matching a search query, e.g. 'branch:fix since:2w -draft'`)
+	sortBy := fs.String("sort", "last-active", "sort by last-active, created, messages or project")
+	limit := fs.Int("limit", 0, "print at most this many sessions")
+	archived := fs.Bool("archived", false, "include archived sessions")
+	fs.Parse(args)
+
+	sortKey, err := filter.ParseSortKey(*sortBy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts := filter.Options{
+		Project:     *project,
+		Branch:      *branch,
+		PathPrefix:  *path,
+		Since:       parseTimeFlag("since", *since),
+		Until:       parseTimeFlag("until", *until),
+		MinMessages: *minMessages,
+		Grep:        *grep,
+		Tag:         *tag,
+	}
+	q, err := filter.ParseQuery(*query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid --query: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *fieldList == "" {
+		if *format == "table" {
+			*fieldList = strings.Join(listing.DefaultTableFields, ",")
+		} else {
+			*fieldList = strings.Join(listing.DefaultRecordFields, ",")
+		}
+	}
+	fields, err := listing.ParseFields(*fieldList)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	loader := newLoader()
+	loader.Archived = *archived
+	ss := loadSessionsWith(loader, loadMeta())
+	mode := filter.Mode{Exact: true}
+	if opts.Grep != "" || q.SearchesText() {
+		idx := syncedIndex(ss)
+		opts.Index, mode.Index = idx, idx
+	}
+	ss = q.Filter(filter.Apply(ss, opts), mode)
+	filter.Sort(ss, sortKey)
+	if *limit > 0 && len(ss) > *limit {
+		ss = ss[:*limit]
+	}
+	if err := listing.Write(os.Stdout, ss, fields, *format, cfg.PriceTable()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// syncedIndex opens the full-text index and brings it up to date with ss.
+func syncedIndex(ss []sessions.Session) *index.Index {
+	paths := make([]string, len(ss))
+	for i, s := range ss {
+		paths[i] = s.FilePath
+	}
+	idx := index.Open()
+	if err := idx.Sync(paths); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: full-text index: %v\n", err)
+	}
+	idx.Save()
+	return idx
+}
+
+// parseTimeFlag parses a --since/--until style value, exiting on error. An
+// empty value yields the zero time.
+func parseTimeFlag(name, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := sessions.ParseTime(value, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: --%s: %v\n", name, err)
+		os.Exit(1)
+	}
+	return t
+}
+
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	by := fs.String("by", "day", "group by day, week, project, branch or model; day and week count all of a session's usage on the day or week it was last active")
+	since := fs.String("since", "", "only sessions active since, e.g. 7d, 2w, 2025-01-01")
+	until := fs.String("until", "", "only sessions active before, e.g. 1d, 2025-02-01")
+	format := fs.String("format", "table", "output format: table, json or csv")
+	fs.Parse(args)
+
+	groupBy, err := stats.ParseGroupBy(*by)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	ss := filter.Apply(loadSessions(), filter.Options{
+		Since: parseTimeFlag("since", *since),
+		Until: parseTimeFlag("until", *until),
+	})
+	rep := stats.Aggregate(ss, groupBy, cfg.PriceTable())
+
+	switch *format {
+	case "table":
+		err = stats.WriteTable(os.Stdout, rep)
+	case "json":
+		err = stats.WriteJSON(os.Stdout, rep)
+	case "csv":
+		err = stats.WriteCSV(os.Stdout, rep)
+	default:
+		err = fmt.Errorf("unknown format %q (want table, json or csv)", *format)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runResume resumes the session identified by ref: a session ID or unique ID
+// prefix, "last", "last@<project>", or text from the summary. When ref is
+// ambiguous the candidates are listed instead.
+func runResume(ref string, skipPermissions, useWorktree bool) {
 	ss := loadSessions()
+	s := resolveSession(ss, ref)
+	if latest := sessions.LatestPart(ss, s); latest.ID != s.ID {
+		fmt.Fprintf(os.Stderr, "Resuming %s, the latest part of the chain %s belongs to\n", latest.ID, s.ID)
+		s = latest
+	}
+	if useWorktree {
+		worktreeResume(s, skipPermissions)
+	} else {
+		resumeSession(s, skipPermissions)
+	}
+}
 
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "PROJECT\tSUMMARY\tBRANCH\tLAST ACTIVE\tSESSION ID")
-	for _, s := range ss {
-		summary := s.Summary
-		if len(summary) > 60 {
-			summary = summary[:57] + "..."
+// resolveSession returns the single session matching ref (see
+// filter.Resolve), or lists the candidates and exits if there is not
+// exactly one.
+func resolveSession(ss []sessions.Session, ref string) sessions.Session {
+	matches := filter.Resolve(ss, ref)
+	switch len(matches) {
+	case 0:
+		fmt.Fprintf(os.Stderr, "Session not found: %s\n", ref)
+		os.Exit(1)
+	case 1:
+	default:
+		fmt.Fprintf(os.Stderr, "%q matches %d sessions:\n\n", ref, len(matches))
+		fields, _ := listing.ParseFields(strings.Join(listing.DefaultTableFields, ","))
+		listing.Write(os.Stderr, matches, fields, "table", cfg.PriceTable())
+		fmt.Fprintln(os.Stderr, "\nUse a longer ID prefix or more specific text to pick one.")
+		os.Exit(1)
+	}
+	return matches[0]
+}
+
+// parseArgs parses flags that may appear before, between or after positional
+// arguments, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
-			s.Project, summary, s.GitBranch, s.TimeAgo(), s.ID)
+		positional = append(positional, args[0])
+		args = args[1:]
 	}
-	w.Flush()
 }
 
-func runResume(sessionID string) {
+// runRename sets a session's custom title. An empty title restores the
+// summary derived from the session.
+func runRename(ref, title string) {
+	store, err := meta.Open()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	loader := newLoader()
+	loader.Archived = true
+	s := resolveSession(loadSessionsWith(loader, store), ref)
+
+	e := store.Get(s.ID)
+	e.Title = title
+	store.Set(s.ID, e)
+	if err := store.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if strings.TrimSpace(title) == "" {
+		fmt.Printf("Cleared title of %s\n", s.ID)
+		return
+	}
+	fmt.Printf("Renamed %s to %q\n", s.ID, store.Get(s.ID).Title)
+}
+
+// runRm archives the given sessions. Their files are moved to the archive
+// directory rather than deleted, so `restore` can bring them back.
+func runRm(refs []string) {
 	ss := loadSessions()
+	var targets []sessions.Session
+	for _, ref := range refs {
+		targets = append(targets, resolveSession(ss, ref))
+	}
+
+	failed := false
+	for _, s := range targets {
+		dest, err := sessions.Archive(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", s.ID, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Archived %s (%s) to %s\n", s.ID, s.Summary, dest)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// runRestore moves archived sessions back into their projects directory.
+func runRestore(refs []string) {
+	loader := newLoader()
+	loader.Archived = true
+	var archived []sessions.Session
+	for _, s := range loadSessionsWith(loader, loadMeta()) {
+		if s.Archived {
+			archived = append(archived, s)
+		}
+	}
+
+	var targets []sessions.Session
+	for _, ref := range refs {
+		targets = append(targets, resolveSession(archived, ref))
+	}
+
+	failed := false
+	for _, s := range targets {
+		dest, err := sessions.Restore(s)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error restoring %s: %v\n", s.ID, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("Restored %s (%s) to %s\n", s.ID, s.Summary, dest)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
 
-	for _, s := range ss {
-		if s.ID == sessionID {
-			resumeSession(s, false)
-			return
+// runPrune archives sessions matching a retention policy. Without --apply it
+// only reports what would be archived.
+func runPrune(args []string) {
+	fs := flag.NewFlagSet("prune", flag.ExitOnError)
+	olderThan := fs.String("older-than", "", "sessions last active before, e.g. 90d, 2025-01-01")
+	maxMessages := fs.Int("max-messages", 0, "sessions with at most this many messages")
+	missingPath := fs.Bool("missing-path", false, "sessions whose project path no longer exists")
+	keep := fs.Int("keep", 0, "keep only the newest N sessions per project")
+	apply := fs.Bool("apply", false, "archive the sessions instead of printing a dry run")
+	fs.Parse(args)
+
+	policy := prune.Policy{
+		OlderThan:   parseTimeFlag("older-than", *olderThan),
+		MaxMessages: *maxMessages,
+		MissingPath: *missingPath,
+		Keep:        *keep,
+	}
+	if policy.Empty() {
+		fmt.Fprintln(os.Stderr, "Usage: claude-manager prune [--older-than 90d] [--max-messages N] [--missing-path] [--keep N] [--apply]")
+		os.Exit(1)
+	}
+
+	candidates := prune.Plan(loadSessions(), policy)
+	if len(candidates) == 0 {
+		fmt.Println("Nothing to prune.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSUMMARY\tLAST ACTIVE\tMESSAGES\tSIZE\tREASON\tSESSION ID")
+	var total int64
+	for _, c := range candidates {
+		s := c.Session
+		summary := sessions.Truncate(s.Summary, 50)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+			s.Project, summary, s.TimeAgo(), s.MessageCount,
+			prune.FormatBytes(c.Size), strings.Join(c.Reasons, ", "), s.ID)
+		total += c.Size
+	}
+	w.Flush()
+	fmt.Println()
+
+	if !*apply {
+		fmt.Printf("Would archive %d session(s), moving %s out of the projects directory. Re-run with --apply to archive.\n",
+			len(candidates), prune.FormatBytes(total))
+		return
+	}
+
+	archived, moved := 0, int64(0)
+	for _, c := range candidates {
+		if _, err := sessions.Archive(c.Session); err != nil {
+			fmt.Fprintf(os.Stderr, "Error archiving %s: %v\n", c.Session.ID, err)
+			continue
 		}
+		archived++
+		moved += c.Size
+	}
+	fmt.Printf("Archived %d session(s), moving %s out of the projects directory. Use `claude-manager restore <id>` to undo.\n",
+		archived, prune.FormatBytes(moved))
+	if archived < len(candidates) {
+		os.Exit(1)
 	}
+}
 
-	fmt.Fprintf(os.Stderr, "Session not found: %s\n", sessionID)
-	os.Exit(1)
+func runExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "md", "output format: "+strings.Join(export.Formats, ", "))
+	output := fs.String("o", "", "write to this file instead of stdout")
+	refs := parseArgs(fs, args)
+	if len(refs) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: claude-manager export <session-id> [--format md|html|json] [-o file]")
+		os.Exit(1)
+	}
+
+	s := resolveSession(loadSessions(), strings.Join(refs, " "))
+	turns, err := sessions.LoadTranscript(s.FilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", s.FilePath, err)
+		os.Exit(1)
+	}
+
+	// Render before touching -o, so a bad format or unreadable transcript
+	// leaves an existing file alone.
+	var buf bytes.Buffer
+	if err := export.Write(&buf, s, turns, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	_, err = f.Write(buf.Bytes())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *output, err)
+		os.Exit(1)
+	}
 }
 
 func worktreeResume(s sessions.Session, skipPermissions bool) {
@@ -163,16 +640,12 @@ func worktreeResume(s sessions.Session, skipPermissions bool) {
 	}
 
 	// Symlink the session file so Claude can find it from the worktree path.
-	// Claude stores sessions in ~/.claude/projects/<encoded-path>/ where the
-	// encoded path replaces "/" with "-". The worktree has a different path
-	// than the original repo, so we need to link the session file over.
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error finding home dir: %v\n", err)
-		os.Exit(1)
-	}
-	worktreeEncoded := strings.ReplaceAll(worktreePath, "/", "-")
-	worktreeProjectDir := filepath.Join(homeDir, ".claude", "projects", worktreeEncoded)
+	// Claude stores sessions in <root>/projects/<encoded-path>/ where the
+	// encoded path replaces every non-alphanumeric character with "-". The
+	// worktree has a different path than the original repo, so we need to
+	// link the session file over, within the session's own root.
+	worktreeEncoded := sessions.EncodeProjectPath(worktreePath)
+	worktreeProjectDir := filepath.Join(s.ConfigDir, "projects", worktreeEncoded)
 	if err := os.MkdirAll(worktreeProjectDir, 0755); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating project dir: %v\n", err)
 		os.Exit(1)
@@ -201,14 +674,14 @@ func worktreeResume(s sessions.Session, skipPermissions bool) {
 	}
 
 	fmt.Printf("Resuming session in worktree %s...\n", worktreePath)
-	err = syscall.Exec(claudePath, claudeArgs, os.Environ())
+	err = syscall.Exec(claudePath, claudeArgs, sessions.ClaudeEnv(os.Environ(), s.ConfigDir))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error exec: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func worktreeNewSession(projectPath string, skipPermissions bool) {
+func worktreeNewSession(projectPath, configDir string, skipPermissions bool) {
 	// Find git repo root
 	cmd := exec.Command("git", "-C", projectPath, "rev-parse", "--show-toplevel")
 	out, err := cmd.Output()
@@ -263,14 +736,14 @@ func worktreeNewSession(projectPath string, skipPermissions bool) {
 		claudeArgs = append(claudeArgs, "--dangerously-skip-permissions")
 	}
 
-	err = syscall.Exec(claudePath, claudeArgs, os.Environ())
+	err = syscall.Exec(claudePath, claudeArgs, sessions.ClaudeEnv(os.Environ(), configDir))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error exec: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func startNewSession(projectPath string, skipPermissions bool) {
+func startNewSession(projectPath, configDir string, skipPermissions bool) {
 	claudePath, err := exec.LookPath("claude")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: 'claude' not found in PATH\n")
@@ -289,7 +762,7 @@ func startNewSession(projectPath string, skipPermissions bool) {
 		claudeArgs = append(claudeArgs, "--dangerously-skip-permissions")
 	}
 
-	err = syscall.Exec(claudePath, claudeArgs, os.Environ())
+	err = syscall.Exec(claudePath, claudeArgs, sessions.ClaudeEnv(os.Environ(), configDir))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error exec: %v\n", err)
 		os.Exit(1)
@@ -319,8 +792,9 @@ func resumeSession(s sessions.Session, skipPermissions bool) {
 		claudeArgs = append(claudeArgs, "--dangerously-skip-permissions")
 	}
 
-	// Replace this process with claude -r <session-id>
-	err = syscall.Exec(claudePath, claudeArgs, os.Environ())
+	// Replace this process with claude -r <session-id>, pointed at the
+	// session's data root
+	err = syscall.Exec(claudePath, claudeArgs, sessions.ClaudeEnv(os.Environ(), s.ConfigDir))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error exec: %v\n", err)
 		os.Exit(1)